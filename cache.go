@@ -0,0 +1,41 @@
+package seal
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// A pageCache holds the output of a page which does not depend on the request.
+// It is rendered on the first request, so it includes state which is published with the snapshot (see Publisher).
+// Each snapshot has its own handlers, so the cache lives as long as the snapshot.
+type pageCache struct {
+	once sync.Once
+	body []byte
+	etag string // strong validator
+	err  error
+}
+
+func (c *pageCache) load(render func(*bytes.Buffer) error) ([]byte, string, error) {
+	c.once.Do(func() {
+		var buf bytes.Buffer
+		if c.err = render(&buf); c.err != nil {
+			return
+		}
+		sum := sha256.Sum256(buf.Bytes())
+		c.body = buf.Bytes()
+		c.etag = `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+	})
+	return c.body, c.etag, c.err
+}
+
+// serve sends the cached body. http.ServeContent replies with 304 Not Modified to matching If-None-Match or If-Modified-Since headers.
+// The lastMod time is sent as Last-Modified header, unless it is zero.
+func (c *pageCache) serve(w http.ResponseWriter, r *http.Request, body []byte, etag string, lastMod time.Time) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("ETag", etag)
+	http.ServeContent(w, r, "", lastMod, bytes.NewReader(body))
+}
@@ -0,0 +1,54 @@
+package seal
+
+import (
+	"strings"
+)
+
+// pageURLPath returns the URL path of the page of the directory urlpath, according to srv.TrailingSlash.
+func (srv *Server) pageURLPath(urlpath string) string {
+	if srv.TrailingSlash && urlpath != "/" {
+		return urlpath + "/"
+	}
+	return urlpath
+}
+
+// addKnown collects the URL paths which are served by the snapshot, so canonical can look them up.
+// Handler directories are known by their root, and by their routes if they are Listers.
+func (snap *snapshot) addKnown() {
+	snap.known = make(map[string]bool)
+	for _, r := range snap.routes {
+		snap.known[r.URLPath] = true
+	}
+	for _, mount := range snap.mounts {
+		snap.known[mount+"/"] = true
+	}
+	for _, from := range snap.redirected {
+		snap.known[from] = true
+	}
+}
+
+// canonical returns the canonical form of an unknown urlpath, if there is one. It toggles the trailing slash and, if lowercase is true, lowercases urlpath.
+// Unknown URL paths without a canonical form are left to the ServeMux, e.g. for handler directories which are no Listers.
+func (snap *snapshot) canonical(urlpath string, lowercase bool) (string, bool) {
+	if snap.known[urlpath] {
+		return "", false
+	}
+
+	var toggled string
+	if trimmed, ok := strings.CutSuffix(urlpath, "/"); ok {
+		toggled = trimmed
+	} else {
+		toggled = urlpath + "/"
+	}
+
+	candidates := []string{toggled}
+	if lowercase {
+		candidates = append(candidates, strings.ToLower(urlpath), strings.ToLower(toggled))
+	}
+	for _, c := range candidates {
+		if c != urlpath && c != "" && snap.known[c] {
+			return c, true
+		}
+	}
+	return "", false
+}
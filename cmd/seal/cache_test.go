@@ -0,0 +1,94 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/wansing/seal"
+	"github.com/wansing/seal/content"
+	"github.com/wansing/seal/handlers/miniblog"
+)
+
+func TestCaching(t *testing.T) {
+	layoutTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	pageTime := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	cacheFS := fstest.MapFS{
+		"html.html":          {Data: []byte(`{{template "main" .}}`), ModTime: layoutTime},
+		"main.md":            {Data: []byte("# Home"), ModTime: layoutTime},
+		"page/main.md":       {Data: []byte("# Page"), ModTime: pageTime},
+		"random/main.random": {Data: []byte("a\nb\n")},
+	}
+	cacheSrv := &seal.Server{
+		FS: cacheFS,
+		Content: map[string]seal.ContentFunc{
+			".html":   content.HTML,
+			".md":     content.Commonmark,
+			".random": content.RandomHTML,
+		},
+	}
+	cacheSrv.Reload()
+
+	get := func(urlpath string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, urlpath, nil)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		rec := httptest.NewRecorder()
+		cacheSrv.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/page", nil)
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" || rec.Body.String() != "<h1 id=\"page\">Page</h1>\n" {
+		t.Fatalf("unexpected response: %d %q %q", rec.Code, etag, rec.Body.String())
+	}
+	if got, want := rec.Header().Get("Last-Modified"), pageTime.Format(http.TimeFormat); got != want {
+		t.Fatalf("expected Last-Modified %s, got %s", want, got)
+	}
+	if got, want := get("/", nil).Header().Get("Last-Modified"), layoutTime.Format(http.TimeFormat); got != want {
+		t.Fatalf("expected Last-Modified %s, got %s", want, got)
+	}
+
+	if rec := get("/page", http.Header{"If-None-Match": {etag}}); rec.Code != http.StatusNotModified {
+		t.Fatalf("If-None-Match: expected 304, got %d", rec.Code)
+	}
+	if rec := get("/page", http.Header{"If-Modified-Since": {pageTime.Format(http.TimeFormat)}}); rec.Code != http.StatusNotModified {
+		t.Fatalf("If-Modified-Since: expected 304, got %d", rec.Code)
+	}
+	if rec := get("/page", http.Header{"If-Modified-Since": {layoutTime.Format(http.TimeFormat)}}); rec.Code != http.StatusOK {
+		t.Fatalf("older If-Modified-Since: expected 200, got %d", rec.Code)
+	}
+	if rec := get("/page", http.Header{"If-None-Match": {`"other"`}}); rec.Code != http.StatusOK {
+		t.Fatalf("other ETag: expected 200, got %d", rec.Code)
+	}
+
+	// dynamic pages are not cached
+	if rec := get("/random", nil); rec.Code != http.StatusOK || rec.Header().Get("ETag") != "" || rec.Header().Get("Last-Modified") != "" {
+		t.Fatalf("dynamic page: unexpected response: %d %v", rec.Code, rec.Header())
+	}
+
+	// changed layout affects inheriting pages
+	newTime := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	cacheFS["html.html"] = &fstest.MapFile{Data: []byte(`<main>{{template "main" .}}</main>`), ModTime: newTime}
+	cacheSrv.Reload()
+	rec = get("/page", http.Header{"If-None-Match": {etag}})
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag || rec.Body.String() != "<main><h1 id=\"page\">Page</h1>\n</main>" {
+		t.Fatalf("after reload: unexpected response: %d %q", rec.Code, rec.Body.String())
+	}
+	if got, want := rec.Header().Get("Last-Modified"), newTime.Format(http.TimeFormat); got != want {
+		t.Fatalf("after reload: expected Last-Modified %s, got %s", want, got)
+	}
+
+	// published state is at least as recent as the reload
+	cacheSrv.Publishers = []seal.Publisher{&miniblog.Miniblog{}}
+	reloaded := time.Now().Truncate(time.Second)
+	cacheSrv.Reload()
+	rec = get("/page", http.Header{"If-Modified-Since": {newTime.Format(http.TimeFormat)}})
+	if lastMod, err := http.ParseTime(rec.Header().Get("Last-Modified")); rec.Code != http.StatusOK || err != nil || lastMod.Before(reloaded) {
+		t.Fatalf("with publishers: expected Last-Modified after %v, got %d %q", reloaded, rec.Code, rec.Header().Get("Last-Modified"))
+	}
+}
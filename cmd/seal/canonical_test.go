@@ -0,0 +1,92 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/wansing/seal"
+	"github.com/wansing/seal/content"
+	"github.com/wansing/seal/handlers/miniblog"
+)
+
+func TestCanonicalURLs(t *testing.T) {
+	canonicalFS := fstest.MapFS{
+		"html.html":                 {Data: []byte(`{{template "main" .}}`)},
+		"main.md":                   {Data: []byte("# Home")},
+		"404.md":                    {Data: []byte("# Not found")},
+		"about/main.md":             {Data: []byte("# About")},
+		"Logo.PNG":                  {Data: []byte("PNG")},
+		"news.blog/2025-01-01-a.md": {Data: []byte("# Post")},
+	}
+
+	tests := []struct {
+		trailingSlash bool
+		lowercase     bool
+		input         string
+		wantCode      int
+		wantLocation  string
+	}{
+		{false, false, "/about", http.StatusOK, ""},
+		{false, false, "/about/", http.StatusMovedPermanently, "/about"},
+		{false, false, "/about/?q=1", http.StatusMovedPermanently, "/about?q=1"},
+		{false, false, "/About", http.StatusNotFound, ""},
+		{false, false, "/news", http.StatusMovedPermanently, "/news/"},
+		{false, false, "/news/", http.StatusOK, ""},
+		{false, false, "/news/2025-01-01-a/", http.StatusMovedPermanently, "/news/2025-01-01-a"},
+		{false, false, "/Logo.PNG", http.StatusOK, ""},
+		{false, false, "/Logo.PNG/", http.StatusMovedPermanently, "/Logo.PNG"},
+		{false, false, "/", http.StatusOK, ""},
+		{true, false, "/about/", http.StatusOK, ""},
+		{true, false, "/about", http.StatusMovedPermanently, "/about/"},
+		{true, false, "/news", http.StatusMovedPermanently, "/news/"},
+		{true, false, "/Logo.PNG/", http.StatusMovedPermanently, "/Logo.PNG"},
+		{false, true, "/About", http.StatusMovedPermanently, "/about"},
+		{false, true, "/ABOUT/", http.StatusMovedPermanently, "/about"},
+		{false, true, "/NEWS/", http.StatusMovedPermanently, "/news/"},
+		{false, true, "/Logo.PNG", http.StatusOK, ""},
+		{true, true, "/About", http.StatusMovedPermanently, "/about/"},
+	}
+	for _, test := range tests {
+		myBlog := &miniblog.Miniblog{}
+		canonicalSrv := &seal.Server{
+			FS: canonicalFS,
+			Content: map[string]seal.ContentFunc{
+				".html": content.HTML,
+				".md":   content.Commonmark,
+			},
+			Handlers: map[string]seal.HandlerGen{
+				".blog": myBlog.MakeHandler,
+			},
+			TrailingSlash: test.trailingSlash,
+			Lowercase:     test.lowercase,
+		}
+		canonicalSrv.Reload()
+
+		rec := httptest.NewRecorder()
+		canonicalSrv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.input, nil))
+		if rec.Code != test.wantCode || rec.Header().Get("Location") != test.wantLocation {
+			t.Fatalf("%s (trailing slash %t, lowercase %t): expected %d %q, got %d %q", test.input, test.trailingSlash, test.lowercase, test.wantCode, test.wantLocation, rec.Code, rec.Header().Get("Location"))
+		}
+	}
+
+	// other methods than GET and HEAD keep their method
+	canonicalSrv := &seal.Server{
+		FS: canonicalFS,
+		Content: map[string]seal.ContentFunc{
+			".md": content.Commonmark,
+		},
+	}
+	canonicalSrv.Reload()
+	for method, wantCode := range map[string]int{
+		http.MethodHead: http.StatusMovedPermanently,
+		http.MethodPost: http.StatusPermanentRedirect,
+	} {
+		rec := httptest.NewRecorder()
+		canonicalSrv.ServeHTTP(rec, httptest.NewRequest(method, "/about/", nil))
+		if rec.Code != wantCode || rec.Header().Get("Location") != "/about" {
+			t.Fatalf("%s /about/: expected %d, got %d %q", method, wantCode, rec.Code, rec.Header().Get("Location"))
+		}
+	}
+}
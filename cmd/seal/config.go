@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+const defaultSecret = "change-me"
+
+// Config is read from (in ascending priority) the defaults, an optional JSON config file, environment variables and command line flags.
+// The environment variable of a flag is its name in upper case with prefix "SEAL_" and dashes replaced by underscores, e.g. SEAL_RELOAD_SECRET.
+type Config struct {
+	ConfigFile         string   `json:"-"`
+	Listen             string   `json:"listen"`
+	BaseURL            string   `json:"base-url"` // for absolute URLs, e.g. in feeds and the sitemap
+	Root               string   `json:"root"`
+	ReloadSecret       string   `json:"reload-secret"`
+	AllowDefaultSecret bool     `json:"allow-default-secret"`
+	GitDir             string   `json:"git-dir"` // default: Root
+	Content            []string `json:"content"` // enabled content file extensions
+	Handlers           []string `json:"handlers"`
+	ErrorsPath         string   `json:"errors-path"` // empty disables the endpoint
+	ReloadPath         string   `json:"reload-path"`
+	GitReloadPath      string   `json:"git-reload-path"`
+	SearchPath         string   `json:"search-path"`
+	SafeReload         bool     `json:"safe-reload"`
+	SitemapStatic      bool     `json:"sitemap-static"`
+	TrailingSlash      bool     `json:"trailing-slash"`
+	Lowercase          bool     `json:"lowercase"`
+	Languages          []string `json:"languages"` // the first one is the default
+	Watch              duration `json:"watch"`     // zero disables watching
+}
+
+// duration is a time.Duration which is read from strings like "2s".
+type duration time.Duration
+
+func (d duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = duration(v)
+	return nil
+}
+
+func defaultConfig() Config {
+	return Config{
+		Listen:        "127.0.0.1:8080",
+		Root:          ".",
+		ReloadSecret:  defaultSecret,
+		Content:       []string{".calendar-agenda", ".calendar-bs5", ".countdown", ".html", ".latest", ".md", ".random", ".search"},
+		Handlers:      []string{".blog"},
+		ErrorsPath:    "/errors",
+		ReloadPath:    "/reload",
+		GitReloadPath: "/git-reload",
+		SearchPath:    "/search.json",
+	}
+}
+
+// listValue is a comma-separated flag.Value.
+type listValue struct {
+	list *[]string
+}
+
+func (v listValue) String() string {
+	if v.list == nil {
+		return ""
+	}
+	return strings.Join(*v.list, ",")
+}
+
+func (v listValue) Set(s string) error {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	*v.list = list
+	return nil
+}
+
+func (cfg *Config) flagSet() *flag.FlagSet {
+	fset := flag.NewFlagSet("seal", flag.ContinueOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "usage: seal [flags] [export <outdir>]")
+		fset.PrintDefaults()
+	}
+	fset.StringVar(&cfg.ConfigFile, "config", cfg.ConfigFile, "JSON config file")
+	fset.StringVar(&cfg.Listen, "listen", cfg.Listen, "listen address")
+	fset.StringVar(&cfg.BaseURL, "base-url", cfg.BaseURL, "base URL like https://example.com for absolute URLs, derived from the request if empty")
+	fset.StringVar(&cfg.Root, "root", cfg.Root, "content root directory")
+	fset.StringVar(&cfg.ReloadSecret, "reload-secret", cfg.ReloadSecret, "secret for the reload endpoints")
+	fset.BoolVar(&cfg.AllowDefaultSecret, "allow-default-secret", cfg.AllowDefaultSecret, "allow the default reload secret")
+	fset.StringVar(&cfg.GitDir, "git-dir", cfg.GitDir, "directory of the git working copy (default: root)")
+	fset.Var(listValue{&cfg.Content}, "content", "comma-separated list of enabled content file extensions")
+	fset.Var(listValue{&cfg.Handlers}, "handlers", "comma-separated list of enabled handler directory extensions")
+	fset.StringVar(&cfg.ErrorsPath, "errors-path", cfg.ErrorsPath, "URL path of the errors endpoint, empty disables it")
+	fset.StringVar(&cfg.ReloadPath, "reload-path", cfg.ReloadPath, "URL path of the reload endpoint, empty disables it")
+	fset.StringVar(&cfg.GitReloadPath, "git-reload-path", cfg.GitReloadPath, "URL path of the git reload endpoint, empty disables it")
+	fset.StringVar(&cfg.SearchPath, "search-path", cfg.SearchPath, "URL path of the JSON search endpoint, empty disables it")
+	fset.BoolVar(&cfg.SafeReload, "safe-reload", cfg.SafeReload, "keep serving the previous content if a reload produces errors")
+	fset.BoolVar(&cfg.SitemapStatic, "sitemap-static", cfg.SitemapStatic, "add static files to the sitemap")
+	fset.BoolVar(&cfg.TrailingSlash, "trailing-slash", cfg.TrailingSlash, "URL paths of pages end with a slash, requests without it are redirected (and vice versa)")
+	fset.BoolVar(&cfg.Lowercase, "lowercase", cfg.Lowercase, "redirect unknown URL paths to their lowercase form")
+	fset.Var(listValue{&cfg.Languages}, "languages", "comma-separated list of languages of content file variants like main.de.md, the first one is the default")
+	fset.TextVar(&cfg.Watch, "watch", cfg.Watch, "interval for watching the content root for changes like 2s, zero disables watching")
+	return fset
+}
+
+// loadConfig reads the config and returns it along with the remaining command line arguments.
+func loadConfig(args []string) (Config, []string, error) {
+	// find config file, which has a lower priority than the environment and the other flags
+	var pre = defaultConfig()
+	configFileFlags := pre.flagSet()
+	configFileFlags.SetOutput(io.Discard)
+	applyEnv(configFileFlags)
+	configFileFlags.Parse(args) // errors are reported below
+
+	var cfg = defaultConfig()
+	if pre.ConfigFile != "" {
+		data, err := os.ReadFile(pre.ConfigFile)
+		if err != nil {
+			return cfg, nil, fmt.Errorf("reading config file: %w", err)
+		}
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return cfg, nil, fmt.Errorf("decoding config file: %w", err)
+		}
+	}
+
+	fset := cfg.flagSet()
+	if err := applyEnv(fset); err != nil {
+		return cfg, nil, err
+	}
+	if err := fset.Parse(args); err != nil {
+		return cfg, nil, err
+	}
+
+	if cfg.GitDir == "" {
+		cfg.GitDir = cfg.Root
+	}
+	return cfg, fset.Args(), nil
+}
+
+// applyEnv sets the flags from environment variables.
+func applyEnv(fset *flag.FlagSet) error {
+	var err error
+	fset.VisitAll(func(f *flag.Flag) {
+		name := "SEAL_" + strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		if value, ok := os.LookupEnv(name); ok && err == nil {
+			if setErr := f.Value.Set(value); setErr != nil {
+				err = fmt.Errorf("environment variable %s: %w", name, setErr)
+			}
+		}
+	})
+	return err
+}
+
+// checkSecret returns an error if a reload endpoint is enabled and the secret is insecure.
+// An empty secret is always refused, because requests without secret would match it.
+func (cfg Config) checkSecret() error {
+	if cfg.ReloadPath == "" && cfg.GitReloadPath == "" {
+		return nil
+	}
+	if cfg.ReloadSecret == "" {
+		return errors.New("refusing to start with an empty reload secret, set -reload-secret")
+	}
+	if cfg.ReloadSecret == defaultSecret && !cfg.AllowDefaultSecret {
+		return errors.New("refusing to start with the default reload secret, set -reload-secret or -allow-default-secret")
+	}
+	return nil
+}
+
+func (cfg Config) contentEnabled(ext string) bool {
+	return slices.Contains(cfg.Content, ext)
+}
+
+func (cfg Config) handlerEnabled(ext string) bool {
+	return slices.Contains(cfg.Handlers, ext)
+}
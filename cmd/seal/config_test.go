@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestConfigPriority(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "seal.json")
+	err := os.WriteFile(configFile, []byte(`{
+		"listen": "127.0.0.1:9000",
+		"root": "file-root",
+		"reload-secret": "file-secret",
+		"content": [".md"],
+		"watch": "5s"
+	}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("SEAL_CONFIG", configFile)
+	t.Setenv("SEAL_ROOT", "env-root")
+	t.Setenv("SEAL_RELOAD_SECRET", "env-secret")
+
+	cfg, args, err := loadConfig([]string{"-reload-secret", "flag-secret", "-handlers", ".blog,.wiki", "export", "out"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Listen != "127.0.0.1:9000" {
+		t.Fatalf("listen: expected value from config file, got %s", cfg.Listen)
+	}
+	if cfg.Root != "env-root" || cfg.GitDir != "env-root" {
+		t.Fatalf("root: expected value from environment, got %s and git-dir %s", cfg.Root, cfg.GitDir)
+	}
+	if cfg.ReloadSecret != "flag-secret" {
+		t.Fatalf("reload secret: expected value from flag, got %s", cfg.ReloadSecret)
+	}
+	if !slices.Equal(cfg.Content, []string{".md"}) || !slices.Equal(cfg.Handlers, []string{".blog", ".wiki"}) {
+		t.Fatalf("got content %v and handlers %v", cfg.Content, cfg.Handlers)
+	}
+	if time.Duration(cfg.Watch) != 5*time.Second {
+		t.Fatalf("watch: got %v", cfg.Watch)
+	}
+	if !slices.Equal(args, []string{"export", "out"}) {
+		t.Fatalf("args: got %v", args)
+	}
+}
+
+func TestConfigWatchOptIn(t *testing.T) {
+	cfg, _, err := loadConfig(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Watch != 0 {
+		t.Fatalf("watch: expected disabled by default, got %v", cfg.Watch)
+	}
+}
+
+func TestConfigDefaultSecret(t *testing.T) {
+	cfg := defaultConfig()
+	if cfg.checkSecret() == nil {
+		t.Fatal("expected default secret to be refused")
+	}
+	cfg.AllowDefaultSecret = true
+	if cfg.checkSecret() != nil {
+		t.Fatal("expected default secret to be allowed")
+	}
+	cfg.ReloadSecret = ""
+	if cfg.checkSecret() == nil {
+		t.Fatal("expected empty secret to be refused even if the default secret is allowed")
+	}
+	cfg = defaultConfig()
+	cfg.ReloadPath = ""
+	cfg.GitReloadPath = ""
+	if cfg.checkSecret() != nil {
+		t.Fatal("expected default secret to be irrelevant without reload endpoints")
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/wansing/seal"
+	"github.com/wansing/seal/content"
+	"github.com/wansing/seal/handlers/miniblog"
+)
+
+type failData struct{}
+
+func (failData) Check(u *url.URL) (string, error) {
+	if u.Query().Has("fail") {
+		return "", errors.New("failing as requested")
+	}
+	return "ok", nil
+}
+
+// failContent is a dynamic content type which fails if the query string contains "fail".
+func failContent(t *template.Template, urlpath, fileroot string, filecontent []byte) error {
+	content.MarkDynamic(t)
+	return content.ParseWithData(t, `{{.Check $.RequestURL}}`, func() failData { return failData{} })
+}
+
+func TestRuntimeError(t *testing.T) {
+	errFS := fstest.MapFS{
+		"html.html":      {Data: []byte(`<title>Site</title><main>{{template "main" .}}</main>`)},
+		"main.html":      {Data: []byte(`begin {{template "check" .}} end`)},
+		"check.fail":     {},
+		"sub/main.html":  {Data: []byte(`sub {{template "check" .}}`)},
+		"sub/check.fail": {},
+		"sub/500.md":     {Data: []byte("---\ntitle: ignored\n---\n# Sorry")},
+	}
+	errSrv := &seal.Server{
+		FS: errFS,
+		Content: map[string]seal.ContentFunc{
+			".fail": failContent,
+			".html": content.HTML,
+			".md":   content.Commonmark,
+		},
+	}
+	if err := errSrv.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input    string
+		wantCode int
+		want     string
+	}{
+		{"/", http.StatusOK, "<title>Site</title><main>begin ok end</main>"},
+		{"/?fail", http.StatusInternalServerError, "500 internal server error\n"}, // no partial output
+		{"/sub", http.StatusOK, "<title>Site</title><main>sub ok</main>"},
+		{"/sub?fail", http.StatusInternalServerError, "<title>Site</title><main><h1 id=\"sorry\">Sorry</h1>\n</main>"},
+	}
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		errSrv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.input, nil))
+		if rec.Code != test.wantCode || rec.Body.String() != test.want {
+			t.Fatalf("%s: expected %d %q, got %d %q", test.input, test.wantCode, test.want, rec.Code, rec.Body.String())
+		}
+	}
+
+	// errors are recorded once per URL path
+	errSrv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/?fail", nil))
+	rec := httptest.NewRecorder()
+	errSrv.ErrorsHandler()(rec, httptest.NewRequest(http.MethodGet, "/errors", nil))
+	var errs []seal.Error
+	if err := json.NewDecoder(rec.Body).Decode(&errs); err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 2 || errs[0].URLPath != "/" || errs[1].URLPath != "/sub" || !strings.Contains(errs[0].Message, "failing as requested") || errs[0].FSPath != "." {
+		t.Fatalf("unexpected errors: %+v", errs)
+	}
+}
+
+func TestHandlerRuntimeError(t *testing.T) {
+	myBlog := &miniblog.Miniblog{}
+	errSrv := &seal.Server{
+		FS: fstest.MapFS{
+			"html.html":                 {Data: []byte(`<main>{{template "main" .}}</main>`)},
+			"main.md":                   {Data: []byte("# Home")},
+			"500.md":                    {Data: []byte("# Sorry")},
+			"news.blog/post.html":       {Data: []byte(`begin {{.Missing}} end`)},
+			"news.blog/2025-01-01-a.md": {Data: []byte("# Post")},
+		},
+		Content: map[string]seal.ContentFunc{
+			".html": content.HTML,
+			".md":   content.Commonmark,
+		},
+		Handlers: map[string]seal.HandlerGen{
+			".blog": myBlog.MakeHandler,
+		},
+	}
+	errSrv.Reload()
+
+	rec := httptest.NewRecorder()
+	errSrv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/news/2025-01-01-a", nil))
+	if want := "<main><h1 id=\"sorry\">Sorry</h1>\n</main>"; rec.Code != http.StatusInternalServerError || rec.Body.String() != want {
+		t.Fatalf("expected 500 %q, got %d %q", want, rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	errSrv.ErrorsHandler()(rec, httptest.NewRequest(http.MethodGet, "/errors", nil))
+	var errs []seal.Error
+	if err := json.NewDecoder(rec.Body).Decode(&errs); err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].URLPath != "/news/2025-01-01-a" || errs[0].FSPath != "news.blog" || !strings.Contains(errs[0].Message, "Missing") {
+		t.Fatalf("unexpected errors: %+v", errs)
+	}
+}
+
+func TestNotFoundPage(t *testing.T) {
+	myBlog := &miniblog.Miniblog{}
+	notFoundSrv := &seal.Server{
+		FS: fstest.MapFS{
+			"html.html":                 {Data: []byte(`<main>{{template "main" .}}</main>`)},
+			"main.md":                   {Data: []byte("# Home")},
+			"404.md":                    {Data: []byte("# Not found")},
+			"docs/main.md":              {Data: []byte("# Docs")},
+			"docs/html.html":            {Data: []byte(`<article>{{template "main" .}}</article>`)},
+			"plain/main.md":             {Data: []byte("# Plain")},
+			"plain/404.html":            {Data: []byte(`{{.Status}} {{.StatusText}} at {{.URLPath}}`)},
+			"news.blog/2025-01-01-a.md": {Data: []byte("# Post")},
+		},
+		Content: map[string]seal.ContentFunc{
+			".html": content.HTML,
+			".md":   content.Commonmark,
+		},
+		Handlers: map[string]seal.HandlerGen{
+			".blog": myBlog.MakeHandler,
+		},
+	}
+	if err := notFoundSrv.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input    string
+		wantCode int
+		want     string
+	}{
+		{"/", http.StatusOK, "<main><h1 id=\"home\">Home</h1>\n</main>"},
+		{"/404", http.StatusNotFound, "<main><h1 id=\"not-found\">Not found</h1>\n</main>"},
+		{"/missing", http.StatusNotFound, "<main><h1 id=\"not-found\">Not found</h1>\n</main>"},
+		{"/docs/missing/deeper", http.StatusNotFound, "<article><h1 id=\"not-found\">Not found</h1>\n</article>"}, // nearest layout
+		{"/plain/missing", http.StatusNotFound, "<main>404 Not Found at /plain</main>"},
+		{"/news/2025-01-01-a", http.StatusOK, ""},
+		{"/docs", http.StatusOK, "<article><h1 id=\"docs\">Docs</h1>\n</article>"},
+		{"/plain", http.StatusOK, "<main><h1 id=\"plain\">Plain</h1>\n</main>"}, // inherited layout
+	}
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		notFoundSrv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.input, nil))
+		if rec.Code != test.wantCode || (test.want != "" && rec.Body.String() != test.want) {
+			t.Fatalf("%s: expected %d %q, got %d %q", test.input, test.wantCode, test.want, rec.Code, rec.Body.String())
+		}
+	}
+
+	// other methods get plain replies
+	for input, wantCode := range map[string]int{
+		"/":        http.StatusMethodNotAllowed,
+		"/missing": http.StatusNotFound,
+		"/docs":    http.StatusMethodNotAllowed,
+	} {
+		rec := httptest.NewRecorder()
+		notFoundSrv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, input, nil))
+		if rec.Code != wantCode || strings.Contains(rec.Body.String(), "<main>") {
+			t.Fatalf("POST %s: expected plain %d, got %d %q", input, wantCode, rec.Code, rec.Body.String())
+		}
+	}
+}
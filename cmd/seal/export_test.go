@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/wansing/seal"
+	"github.com/wansing/seal/content"
+	"github.com/wansing/seal/handlers/miniblog"
+)
+
+func TestExport(t *testing.T) {
+	myBlog := &miniblog.Miniblog{
+		BaseURL: "https://example.org",
+	}
+	exportSrv := &seal.Server{
+		FS: fstest.MapFS{
+			"html.html":                 {Data: []byte(`<main>{{template "main" .}}</main>`)},
+			"main.md":                   {Data: []byte(`# Home`)},
+			"favicon.ico":               {Data: []byte(`ICON`)},
+			"site/main.md":              {Data: []byte(`# Site`)},
+			"events/main.calendar-bs5":  {},
+			"events/sub/main.md":        {Data: []byte(`# Sub`)}, // not dynamic, as it replaces "main"
+			"news.blog/2025-01-01-a.md": {Data: []byte(`# First post`)},
+		},
+		Content: map[string]seal.ContentFunc{
+			".calendar-bs5": content.CalendarBS5{}.Make,
+			".html":         content.HTML,
+			".md":           content.Commonmark,
+		},
+		Handlers: map[string]seal.HandlerGen{
+			".blog": myBlog.MakeHandler,
+		},
+	}
+	exportSrv.Reload()
+
+	outDir := t.TempDir()
+	if _, err := exportSrv.Export(outDir); err == nil {
+		t.Fatal("expected error without base URL")
+	}
+
+	exportSrv.BaseURL = "https://example.org"
+	dynamic, err := exportSrv.Export(outDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(dynamic, []string{"/events"}) {
+		t.Fatalf("expected /events to be reported as dynamic, got %v", dynamic)
+	}
+
+	tests := []struct {
+		filename string
+		want     string
+	}{
+		{"index.html", `<h1 id="home">Home</h1>`},
+		{"favicon.ico", `ICON`},
+		{"site/index.html", `<h1 id="site">Site</h1>`},
+		{"events/sub/index.html", `<h1 id="sub">Sub</h1>`},
+		{"news/index.html", `First post`},
+		{"news/2025-01-01-a/index.html", `<h1 id="first-post">First post</h1>`},
+		{"news/feed.xml", `<link href="https://example.org/news/2025-01-01-a"></link>`},
+		{"sitemap.xml", `<loc>https://example.org/news/2025-01-01-a</loc>`},
+		{"robots.txt", `Sitemap: https://example.org/sitemap.xml`},
+	}
+	for _, test := range tests {
+		got, err := os.ReadFile(filepath.Join(outDir, test.filename))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(got), test.want) {
+			t.Fatalf("%s: expected %s, got %s", test.filename, test.want, got)
+		}
+	}
+}
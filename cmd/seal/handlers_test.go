@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/wansing/seal"
+	"github.com/wansing/seal/content"
+	"github.com/wansing/seal/handlers/miniblog"
+)
+
+// fileHandler serves the content of the file "file.txt" in its filesystem.
+func fileHandler(fsys fs.FS, urlpath string, t *template.Template, contentFuncs map[string]seal.ContentFunc) (http.Handler, error) {
+	data, err := fs.ReadFile(fsys, "file.txt")
+	if err != nil {
+		return nil, err
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	}), nil
+}
+
+func failingHandler(fsys fs.FS, urlpath string, t *template.Template, contentFuncs map[string]seal.ContentFunc) (http.Handler, error) {
+	return nil, errors.New("handler failed")
+}
+
+func TestNestedHandlers(t *testing.T) {
+	myBlog := &miniblog.Miniblog{}
+	nestedSrv := &seal.Server{
+		FS: fstest.MapFS{
+			"html.html":                        {Data: []byte(`<main>{{template "main" .}}</main>`)},
+			"top.file/file.txt":                {Data: []byte(`top`)},
+			"site/sub.file/file.txt":           {Data: []byte(`nested`)},
+			"site/deeper/sub.file/file.txt":    {Data: []byte(`deeper`)},
+			"site/news.blog/2025-01-01-a.md":   {Data: []byte(`# Nested post`)},
+			"site/broken.fail/file.txt":        {Data: []byte(`unused`)},
+			"site/missing.file/other-file.txt": {Data: []byte(`unused`)},
+		},
+		Content: map[string]seal.ContentFunc{
+			".html": content.HTML,
+			".md":   content.Commonmark,
+		},
+		Handlers: map[string]seal.HandlerGen{
+			".blog": myBlog.MakeHandler,
+			".fail": failingHandler,
+			".file": fileHandler,
+		},
+	}
+	nestedSrv.Reload()
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"/top/", "top"},
+		{"/site/sub/", "nested"},
+		{"/site/deeper/sub/", "deeper"},
+		{"/site/news/2025-01-01-a", `<h1 id="nested-post">Nested post</h1>`},
+		{"/site/broken/", "404 page not found"},
+	}
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		nestedSrv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.input, nil))
+		if got := rec.Body.String(); !strings.Contains(got, test.want) {
+			t.Fatalf("%s: expected %s, got %s", test.input, test.want, got)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	nestedSrv.ErrorsHandler()(rec, httptest.NewRequest(http.MethodGet, "/errors", nil))
+	for _, want := range []string{`"message": "handler failed"`, `"fspath": "site/broken.fail"`, `"fspath": "site/missing.file"`} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Fatalf("expected errors to contain %s, got %s", want, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/wansing/seal"
+	"github.com/wansing/seal/content"
+)
+
+func TestLanguages(t *testing.T) {
+	langSrv := &seal.Server{
+		FS: fstest.MapFS{
+			"html.html":           {Data: []byte(`{{.Lang}}:{{.AlternateLinks}}{{template "main" .}}{{template "footer" .}}`)},
+			"footer.html":         {Data: []byte(`{{$.T "Search"}}`)},
+			"main.de.md":          {Data: []byte("# Willkommen")},
+			"main.en.md":          {Data: []byte("---\naliases: [/welcome]\n---\n# Welcome")},
+			"404.md":              {Data: []byte("# Not found")},
+			"404.de.md":           {Data: []byte("# Nicht gefunden")},
+			"about/main.md":       {Data: []byte("# About")},
+			"about/team/main.md":  {Data: []byte("---\nlang: en\n---\n# Team")},
+			"contact/main.de.md":  {Data: []byte("# Kontakt")},
+			"contact/main.en.md":  {Data: []byte("# Contact")},
+			"contact/footer.html": {Data: []byte(`{{nav.URLPathIn $.Lang}} {{nav.TitleIn $.Lang}}`)},
+		},
+		Content: map[string]seal.ContentFunc{
+			".html": content.HTML,
+			".md":   content.Commonmark,
+		},
+		Languages: []string{"de", "en"},
+	}
+	if err := langSrv.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input          string
+		acceptLanguage string
+		wantCode       int
+		wantLanguage   string
+		want           string
+	}{
+		{"/", "", http.StatusOK, "de", `de:<link rel="alternate" hreflang="de" href="/de"><link rel="alternate" hreflang="en" href="/en"><link rel="alternate" hreflang="x-default" href="/"><h1 id="willkommen">Willkommen</h1>` + "\nSuchen"},
+		{"/", "en-US,en;q=0.9,de;q=0.8", http.StatusOK, "en", `en:<link rel="alternate" hreflang="de" href="/de"><link rel="alternate" hreflang="en" href="/en"><link rel="alternate" hreflang="x-default" href="/"><h1 id="welcome">Welcome</h1>` + "\nSearch"},
+		{"/", "fr, de-AT;q=0.5", http.StatusOK, "de", ""},
+		{"/de", "en", http.StatusOK, "de", ""},
+		{"/en", "", http.StatusOK, "en", ""},
+		{"/about", "en", http.StatusOK, "de", "de:<h1 id=\"about\">About</h1>\nSuchen"}, // no variants, default language
+		{"/about/team", "", http.StatusOK, "en", "en:<h1 id=\"team\">Team</h1>\nSearch"},
+		{"/en/contact", "", http.StatusOK, "en", ""},
+		{"/missing", "en", http.StatusNotFound, "", "de:<h1 id=\"nicht-gefunden\">Nicht gefunden</h1>\nSuchen"},
+		{"/en/missing", "", http.StatusNotFound, "", "en:<h1 id=\"not-found\">Not found</h1>\nSearch"},
+		{"/en/about", "", http.StatusNotFound, "", ""},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, test.input, nil)
+		if test.acceptLanguage != "" {
+			req.Header.Set("Accept-Language", test.acceptLanguage)
+		}
+		rec := httptest.NewRecorder()
+		langSrv.ServeHTTP(rec, req)
+		if rec.Code != test.wantCode || rec.Header().Get("Content-Language") != test.wantLanguage || (test.want != "" && rec.Body.String() != test.want) {
+			t.Fatalf("%s (%s): expected %d %q %q, got %d %q %q", test.input, test.acceptLanguage, test.wantCode, test.wantLanguage, test.want, rec.Code, rec.Header().Get("Content-Language"), rec.Body.String())
+		}
+	}
+
+	// translated navigation
+	for urlpath, want := range map[string]string{
+		"/de/contact": "<h1 id=\"kontakt\">Kontakt</h1>\n/de/contact Kontakt",
+		"/en/contact": "<h1 id=\"contact\">Contact</h1>\n/en/contact Contact",
+	} {
+		rec := httptest.NewRecorder()
+		langSrv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, urlpath, nil))
+		if !strings.HasSuffix(rec.Body.String(), want) {
+			t.Fatalf("%s: expected suffix %q, got %q", urlpath, want, rec.Body.String())
+		}
+	}
+
+	// negotiated responses vary
+	rec := httptest.NewRecorder()
+	langSrv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/contact", nil))
+	if rec.Header().Get("Vary") != "Accept-Language" {
+		t.Fatalf("expected Vary header, got %q", rec.Header().Get("Vary"))
+	}
+
+	// aliases of a variant redirect to it
+	rec = httptest.NewRecorder()
+	langSrv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/welcome", nil))
+	if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/en" {
+		t.Fatalf("expected redirect to /en, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+}
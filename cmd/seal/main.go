@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/wansing/seal"
 	"github.com/wansing/seal/content"
@@ -11,29 +13,93 @@ import (
 )
 
 func main() {
-	listen := "127.0.0.1:8080"
-	reloadSecret := "change-me"
-	myBlog := &miniblog.Miniblog{}
+	cfg, args, err := loadConfig(os.Args[1:])
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	myBlog := &miniblog.Miniblog{
+		BaseURL: cfg.BaseURL,
+	}
+	if len(cfg.Languages) > 0 {
+		myBlog.Lang = cfg.Languages[0]
+	}
 
 	srv := &seal.Server{
-		FS: os.DirFS("."),
-		Content: map[string]seal.ContentFunc{
-			".calendar-bs5": content.CalendarBS5{}.Make,
-			".countdown":    content.Countdown,
-			".html":         content.HTML,
-			".md":           content.Commonmark,
-			".random":       content.RandomHTML,
-		},
-		Handlers: map[string]seal.HandlerGen{
-			".blog": myBlog.MakeHandler,
-		},
+		FS:            os.DirFS(cfg.Root),
+		Content:       map[string]seal.ContentFunc{},
+		Handlers:      map[string]seal.HandlerGen{},
+		Publishers:    []seal.Publisher{myBlog},
+		SafeReload:    cfg.SafeReload,
+		BaseURL:       cfg.BaseURL,
+		SitemapStatic: cfg.SitemapStatic,
+		TrailingSlash: cfg.TrailingSlash,
+		Lowercase:     cfg.Lowercase,
+		Languages:     cfg.Languages,
+	}
+
+	builtinContent := map[string]seal.ContentFunc{
+		".calendar-agenda": content.CalendarAgenda{}.Make,
+		".calendar-bs5":    content.CalendarBS5{}.Make,
+		".countdown":       content.Countdown,
+		".html":            content.HTML,
+		".latest":          myBlog.Latest,
+		".md":              content.Commonmark,
+		".random":          content.RandomHTML,
+		".search":          seal.Search,
 	}
+	for ext, contentFunc := range builtinContent {
+		if cfg.contentEnabled(ext) {
+			srv.Content[ext] = contentFunc
+		}
+	}
+
+	builtinHandlers := map[string]seal.HandlerGen{
+		".blog": myBlog.MakeHandler,
+	}
+	for ext, handlerGen := range builtinHandlers {
+		if cfg.handlerEnabled(ext) {
+			srv.Handlers[ext] = handlerGen
+		}
+	}
+
 	srv.Reload()
 
+	if len(args) > 0 && args[0] == "export" {
+		if len(args) != 2 {
+			log.Fatalln("usage: seal [flags] export <outdir>")
+		}
+		dynamic, err := srv.Export(args[1])
+		for _, urlpath := range dynamic {
+			log.Printf("warning: %s depends on the request and has been exported as rendered now", urlpath)
+		}
+		if err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
+	if err := cfg.checkSecret(); err != nil {
+		log.Fatalln(err)
+	}
+
+	if cfg.Watch > 0 {
+		go srv.Watch(context.Background(), time.Duration(cfg.Watch))
+	}
+
 	http.Handle("/", srv)
-	http.HandleFunc("/errors", srv.ErrorsHandler())
-	http.HandleFunc("/reload", seal.ReloadHandler(reloadSecret, srv.Reload))
-	http.HandleFunc("/git-reload", seal.GitReloadHandler(reloadSecret, ".", srv.Reload))
-	log.Printf("listening to %s", listen)
-	http.ListenAndServe(listen, nil)
+	if cfg.ErrorsPath != "" {
+		http.HandleFunc(cfg.ErrorsPath, srv.ErrorsHandler())
+	}
+	if cfg.SearchPath != "" {
+		http.HandleFunc(cfg.SearchPath, srv.SearchHandler())
+	}
+	if cfg.ReloadPath != "" {
+		http.HandleFunc(cfg.ReloadPath, seal.ReloadHandler(cfg.ReloadSecret, srv.Reload))
+	}
+	if cfg.GitReloadPath != "" {
+		http.HandleFunc(cfg.GitReloadPath, seal.GitReloadHandler(cfg.ReloadSecret, cfg.GitDir, srv.Reload))
+	}
+	log.Printf("listening to %s", cfg.Listen)
+	http.ListenAndServe(cfg.Listen, nil)
 }
@@ -53,6 +53,9 @@ var baseFS = fstest.MapFS{
 	"nested-definitions/foo.html": &fstest.MapFile{
 		Data: []byte(`This is ignored. {{define "main"}}This is main.{{end}}`),
 	},
+	"meta/main.html": &fstest.MapFile{
+		Data: []byte("---\ntitle: Meta page\n---\n<p>{{.Meta.Title}}</p>"),
+	},
 	"empty-dir": &fstest.MapFile{
 		Mode: fs.ModeDir,
 	},
@@ -108,6 +111,7 @@ func TestSeal(t *testing.T) {
 		{input: "/site/subsite/not-existing-subsite", want: `404 page not found`},
 		{input: "/nested-definitions", want: `<html><body><main>This is main.</main></body></html>`},
 		{input: "/dir-without-main-template", want: `<html><body><main></main></body></html>`},
+		{input: "/meta", want: `<html><body><main><p>Meta page</p></main></body></html>`},
 		{input: "/empty-dir", want: `404 page not found`},
 		{input: "/other", want: `<html><body><main><h1 id="other-filesystem">Other filesystem</h1>
 </main></body></html>`},
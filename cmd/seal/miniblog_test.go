@@ -0,0 +1,258 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/wansing/seal"
+	"github.com/wansing/seal/content"
+	"github.com/wansing/seal/handlers/miniblog"
+)
+
+func TestMiniblogFeeds(t *testing.T) {
+	myBlog := &miniblog.Miniblog{
+		BaseURL:     "https://example.com",
+		FullContent: true,
+	}
+	blogSrv := &seal.Server{
+		FS: fstest.MapFS{
+			"html.html":                 {Data: []byte(`<head>{{block "head" .}}{{end}}</head><main>{{template "main" .}}</main>`)},
+			"news.blog/index.html":      {Data: []byte("---\ntitle: News\nauthor: Jane Doe\n---\n{{define \"head\"}}{{feedLinks}}{{end}}{{range .Previews}}{{.Title}}{{end}}")},
+			"news.blog/2025-01-01-a.md": {Data: []byte("# First post\n\nFirst paragraph with [a link](other).")},
+			"news.blog/b.md":            {Data: []byte("---\ntitle: Second post\ndate: 2025-02-01\ndescription: About the second post\n---\nSecond paragraph.")},
+			"news.blog/c.md":            {Data: []byte("---\ndate: 2025-03-01\ndraft: true\n---\n# Draft")},
+		},
+		Content: map[string]seal.ContentFunc{
+			".html": content.HTML,
+			".md":   content.Commonmark,
+		},
+		Handlers: map[string]seal.HandlerGen{
+			".blog": myBlog.MakeHandler,
+		},
+	}
+	blogSrv.Reload()
+
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"/news/", []string{
+			`<link rel="alternate" type="application/atom+xml" title="News" href="https://example.com/news/feed.xml">`,
+			"Second postFirst post", // newest first, draft skipped
+		}},
+		{"/news/feed.xml", []string{
+			`<feed xmlns="http://www.w3.org/2005/Atom">`,
+			`<title>News</title>`,
+			"<author>\n\t\t<name>Jane Doe</name>\n\t</author>",
+			`<link href="https://example.com/news/2025-01-01-a"></link>`,
+			`<summary type="text">About the second post</summary>`,
+			`<summary type="text">First paragraph with a link.</summary>`,
+			`<published>2025-02-01T00:00:00`,
+			`href=&#34;https://example.com/news/other&#34;`,
+		}},
+		{"/news/rss.xml", []string{
+			`<rss version="2.0">`,
+			`<guid isPermaLink="true">https://example.com/news/b</guid>`,
+		}},
+	}
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		blogSrv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.input, nil))
+		for _, want := range test.want {
+			if !strings.Contains(rec.Body.String(), want) {
+				t.Fatalf("%s: expected %s, got %s", test.input, want, rec.Body.String())
+			}
+		}
+		if strings.Contains(rec.Body.String(), "Draft") {
+			t.Fatalf("%s: contains draft", test.input)
+		}
+	}
+}
+
+func TestMiniblogEmptyFeed(t *testing.T) {
+	myBlog := &miniblog.Miniblog{}
+	blogSrv := &seal.Server{
+		FS: fstest.MapFS{
+			"news.blog/index.html": {Data: []byte("---\ntitle: News\n---\n")},
+		},
+		Content: map[string]seal.ContentFunc{
+			".html": content.HTML,
+		},
+		Handlers: map[string]seal.HandlerGen{
+			".blog": myBlog.MakeHandler,
+		},
+	}
+	blogSrv.Reload()
+
+	rec := httptest.NewRecorder()
+	blogSrv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/news/feed.xml", nil))
+	if got := rec.Body.String(); !strings.Contains(got, "<updated>"+time.Now().UTC().Format("2006-")) || strings.Contains(got, "0001-01-01") {
+		t.Fatalf("expected the generation time, got %s", got)
+	}
+	if got := rec.Body.String(); !strings.Contains(got, "<name>News</name>") {
+		t.Fatalf("expected the blog title as author, got %s", got)
+	}
+}
+
+func TestMiniblogLang(t *testing.T) {
+	myBlog := &miniblog.Miniblog{
+		Lang: "de",
+	}
+	blogSrv := &seal.Server{
+		FS: fstest.MapFS{
+			"html.html":                 {Data: []byte(`{{template "main" .}}`)},
+			"news.blog/2025-01-01-a.md": {Data: []byte("# Post")},
+			"en.blog/index.html":        {Data: []byte("---\nlang: en\n---\n")},
+			"en.blog/2025-01-01-a.md":   {Data: []byte("# Post")},
+		},
+		Content: map[string]seal.ContentFunc{
+			".html": content.HTML,
+			".md":   content.Commonmark,
+		},
+		Handlers: map[string]seal.HandlerGen{
+			".blog": myBlog.MakeHandler,
+		},
+	}
+	blogSrv.Reload()
+
+	for input, want := range map[string]string{
+		"/news/2025-01-01-a": "Zurück zum Blog",
+		"/en/2025-01-01-a":   "Back to Blog",
+	} {
+		rec := httptest.NewRecorder()
+		blogSrv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, input, nil))
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Fatalf("%s: expected %s, got %s", input, want, rec.Body.String())
+		}
+	}
+}
+
+func TestMiniblogTaxonomy(t *testing.T) {
+	myBlog := &miniblog.Miniblog{
+		PageSize: 2,
+	}
+	blogSrv := &seal.Server{
+		FS: fstest.MapFS{
+			"html.html":                 {Data: []byte(`{{template "main" .}}`)},
+			"news.blog/2024-12-24-a.md": {Data: []byte("---\ntags: [Go, Web]\n---\n# A")},
+			"news.blog/2025-01-01-b.md": {Data: []byte("---\ntags: [Go]\n---\n# B")},
+			"news.blog/2025-01-15-c.md": {Data: []byte("# C")},
+			"news.blog/2025-02-01-d.md": {Data: []byte("# D")},
+			"news.blog/tag.html":        {Data: []byte(`Tag {{.Tag}}:{{range .Previews}} {{.Title}}{{end}}`)},
+			"news.blog/archive.html":    {Data: []byte(`Archive {{.Title}}:{{range .Previews}} {{.Title}}{{end}}`)},
+		},
+		Content: map[string]seal.ContentFunc{
+			".html": content.HTML,
+			".md":   content.Commonmark,
+		},
+		Handlers: map[string]seal.HandlerGen{
+			".blog": myBlog.MakeHandler,
+		},
+	}
+	blogSrv.Reload()
+
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"/news/", []string{">2025-02-01 D<", ">2025-01-15 C<", `href="/news/page/2"`}},
+		{"/news/page/2", []string{">2025-01-01 B<", ">2024-12-24 A<", `href="/news/"`}},
+		{"/news/page/3", []string{"404 page not found"}},
+		{"/news/2025", []string{"Archive 2025: D C B"}},
+		{"/news/2025/01", []string{"Archive 2025-01: C B"}},
+		{"/news/2024", []string{"Archive 2024: A"}},
+		{"/news/tag/Go", []string{"Tag Go: B A"}},
+		{"/news/tag/Web", []string{"Tag Web: A"}},
+		{"/news/2025-01-01-b", []string{`<a href="/news/tag/Go">#Go</a>`}},
+	}
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		blogSrv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.input, nil))
+		for _, want := range test.want {
+			if !strings.Contains(rec.Body.String(), want) {
+				t.Fatalf("%s: expected %s, got %s", test.input, want, rec.Body.String())
+			}
+		}
+	}
+}
+
+func TestMiniblogFileNames(t *testing.T) {
+	myBlog := &miniblog.Miniblog{}
+	blogSrv := &seal.Server{
+		FS: fstest.MapFS{
+			"html.html":                          {Data: []byte(`{{template "main" .}}`)},
+			"my news.blog/2025-01-01-my post.md": {Data: []byte("# Space")},
+			"my news.blog/2025-01-02-a{b}.md":    {Data: []byte("# Braces")},
+		},
+		Content: map[string]seal.ContentFunc{
+			".html": content.HTML,
+			".md":   content.Commonmark,
+		},
+		Handlers: map[string]seal.HandlerGen{
+			".blog": myBlog.MakeHandler,
+		},
+	}
+	blogSrv.Reload()
+
+	for input, want := range map[string]string{
+		"/my%20news/":                     `href="/my%20news/2025-01-01-my%20post"`,
+		"/my%20news/2025-01-01-my%20post": "Space",
+		"/my%20news/2025-01-02-a%7Bb%7D":  "Braces",
+	} {
+		rec := httptest.NewRecorder()
+		blogSrv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, input, nil))
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), want) {
+			t.Fatalf("%s: expected %s, got %d %s", input, want, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestMiniblogLatest(t *testing.T) {
+	myBlog := &miniblog.Miniblog{}
+	blogFS := fstest.MapFS{
+		"html.html":                 {Data: []byte(`{{template "main" .}}`)},
+		"main.html":                 {Data: []byte(`news:{{template "news" .}} events:{{template "events" .}} all:{{template "all" .}}`)},
+		"news.latest":               {Data: []byte("/news 1\n{{range .}} {{.Title}}{{end}}")},
+		"events.latest":             {Data: []byte("/events\n{{range .}} {{.Title}}{{end}}")},
+		"all.latest":                {Data: []byte("{{range .}} {{.Title}}{{end}}")},
+		"news.blog/2025-01-01-a.md": {Data: []byte("# News A")},
+		"news.blog/2025-03-01-b.md": {Data: []byte("# News B")},
+		"events.blog/2025-02-01.md": {Data: []byte("# Event")},
+	}
+	blogSrv := &seal.Server{
+		FS: blogFS,
+		Content: map[string]seal.ContentFunc{
+			".html":   content.HTML,
+			".latest": myBlog.Latest,
+			".md":     content.Commonmark,
+		},
+		Handlers: map[string]seal.HandlerGen{
+			".blog": myBlog.MakeHandler,
+		},
+		Publishers: []seal.Publisher{myBlog},
+	}
+
+	get := func() string {
+		rec := httptest.NewRecorder()
+		blogSrv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec.Body.String()
+	}
+
+	blogSrv.Reload()
+	if got, want := get(), "news: News B events: Event all: News B Event News A"; got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+
+	// remove all blogs
+	delete(blogFS, "news.blog/2025-01-01-a.md")
+	delete(blogFS, "news.blog/2025-03-01-b.md")
+	delete(blogFS, "events.blog/2025-02-01.md")
+	blogSrv.Reload()
+	if got, want := get(), "news: events: all:"; got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/wansing/seal"
+	"github.com/wansing/seal/content"
+	"github.com/wansing/seal/handlers/miniblog"
+)
+
+func TestNav(t *testing.T) {
+	myBlog := &miniblog.Miniblog{}
+	navSrv := &seal.Server{
+		FS: fstest.MapFS{
+			"html.html": {Data: []byte(strings.Join([]string{
+				`menu:{{range nav.Root.Children}} {{.Title}}{{if .IsActive $.URLPath}}*{{end}}{{end}}`,
+				`crumbs:{{range nav.Breadcrumbs}} {{.URLPath}}{{end}}`,
+				`children:{{range nav.Children}} {{.Slug}}{{end}}`,
+				`siblings:{{range nav.Siblings}} {{.Title}}{{if .IsCurrent $.URLPath}}*{{end}}{{end}}`,
+			}, "\n"))},
+			"main.md":                   {Data: []byte("# Home")},
+			"about/main.md":             {Data: []byte("---\ntitle: About us\n---\n# Ignored")},
+			"about/team/main.md":        {Data: []byte("# Our team")},
+			"about/$/sidebar.html":      {Data: []byte("sidebar")},
+			"contact/main.html":         {Data: []byte("<h1>Contact</h1>")},
+			"empty/.keep":               {},
+			".hidden/main.md":           {Data: []byte("# Hidden")},
+			"news.blog/2025-01-01-a.md": {Data: []byte("# Post")},
+		},
+		Content: map[string]seal.ContentFunc{
+			".html": content.HTML,
+			".md":   content.Commonmark,
+		},
+		Handlers: map[string]seal.HandlerGen{
+			".blog": myBlog.MakeHandler,
+		},
+	}
+	if err := navSrv.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"/", "menu: About us Contact news\ncrumbs: /\nchildren: about contact news\nsiblings:"},
+		{"/about", "menu: About us* Contact news\ncrumbs: / /about\nchildren: team\nsiblings: About us* Contact news"},
+		{"/about/team", "menu: About us* Contact news\ncrumbs: / /about /about/team\nchildren:\nsiblings: Our team*"},
+		{"/news/2025-01-01-a", "menu: About us Contact news*\ncrumbs: / /news/\nchildren:\nsiblings: About us Contact news"},
+	}
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		navSrv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.input, nil))
+		if got := rec.Body.String(); !strings.HasPrefix(got, test.want) {
+			t.Fatalf("%s: expected %q, got %q", test.input, test.want, got)
+		}
+	}
+}
+
+func TestNavOrder(t *testing.T) {
+	orderSrv := &seal.Server{
+		FS: fstest.MapFS{
+			"html.html":          {Data: []byte(`{{range nav.Root.Children}} {{.Title}}={{.URLPath}}{{end}}`)},
+			"main.md":            {Data: []byte("# Home")},
+			"02-contact/main.md": {Data: []byte("# Contact")},
+			"01-about/main.md":   {Data: []byte("# About")},
+			"blog/.meta":         {Data: []byte("title: Blog\norder: 3\n")},
+			"blog/main.md":       {Data: []byte("# Ignored")},
+			"imprint/main.md":    {Data: []byte("# Imprint")},
+			"archive/.meta":      {Data: []byte("hidden: true\n")},
+			"archive/main.md":    {Data: []byte("# Archive")},
+			"old/.meta":          {Data: []byte("redirect: /about\n")},
+			"my_news.blog/a.md":  {Data: []byte("# Post")},
+		},
+		Content: map[string]seal.ContentFunc{
+			".html": content.HTML,
+			".md":   content.Commonmark,
+		},
+		Handlers: map[string]seal.HandlerGen{
+			".blog": (&miniblog.Miniblog{}).MakeHandler,
+		},
+	}
+	if err := orderSrv.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input    string
+		wantCode int
+		want     string
+	}{
+		{"/", http.StatusOK, " About=/about Contact=/contact Blog=/blog Imprint=/imprint my_news=/my_news/ old=/old"},
+		{"/archive", http.StatusOK, " About=/about Contact=/contact Blog=/blog Imprint=/imprint my_news=/my_news/ old=/old"}, // hidden from navigation only
+		{"/my_news/", http.StatusOK, ""}, // handler directories keep their name
+		{"/old", http.StatusSeeOther, ""},
+		{"/01-about", http.StatusNotFound, ""},
+	}
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		orderSrv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.input, nil))
+		if rec.Code != test.wantCode {
+			t.Fatalf("%s: expected status %d, got %d", test.input, test.wantCode, rec.Code)
+		}
+		if test.want != "" && rec.Body.String() != test.want {
+			t.Fatalf("%s: expected %q, got %q", test.input, test.want, rec.Body.String())
+		}
+		if test.wantCode == http.StatusSeeOther && rec.Header().Get("Location") != "/about" {
+			t.Fatalf("%s: unexpected redirect to %s", test.input, rec.Header().Get("Location"))
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/wansing/seal"
+	"github.com/wansing/seal/content"
+	"github.com/wansing/seal/handlers/miniblog"
+)
+
+func TestRedirects(t *testing.T) {
+	myBlog := &miniblog.Miniblog{}
+	redirectSrv := &seal.Server{
+		FS: fstest.MapFS{
+			"html.html":                 {Data: []byte(`{{template "main" .}}`)},
+			"main.md":                   {Data: []byte("# Home")},
+			".redirects":                {Data: []byte("# site-wide\n/old-home / 302\n/gone https://example.com/\n/about /news/x\n/news/y /\n/broken\n/invalid / 200\n")},
+			"about/main.md":             {Data: []byte("# About")},
+			"docs/.redirects":           {Data: []byte("intro start 308\nold/ /docs/start\n")},
+			"docs/start/main.md":        {Data: []byte("---\naliases: [begin, /first]\n---\n# Start")},
+			"news.blog/2025-01-01-a.md": {Data: []byte("# Post")},
+		},
+		Content: map[string]seal.ContentFunc{
+			".html": content.HTML,
+			".md":   content.Commonmark,
+		},
+		Handlers: map[string]seal.HandlerGen{
+			".blog": myBlog.MakeHandler,
+		},
+	}
+	redirectSrv.Reload()
+
+	tests := []struct {
+		input        string
+		wantCode     int
+		wantLocation string
+	}{
+		{"/old-home", http.StatusFound, "/"},
+		{"/gone", http.StatusMovedPermanently, "https://example.com/"},
+		{"/docs/intro", http.StatusPermanentRedirect, "/docs/start"},
+		{"/docs/old/", http.StatusMovedPermanently, "/docs/start"},
+		{"/docs/begin", http.StatusMovedPermanently, "/docs/start"},
+		{"/first", http.StatusMovedPermanently, "/docs/start"},
+		{"/about", http.StatusOK, ""},        // conflicts with page
+		{"/news/y", http.StatusNotFound, ""}, // conflicts with handler directory
+		{"/docs/start", http.StatusOK, ""},
+	}
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		redirectSrv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.input, nil))
+		if rec.Code != test.wantCode || rec.Header().Get("Location") != test.wantLocation {
+			t.Fatalf("%s: expected %d %q, got %d %q", test.input, test.wantCode, test.wantLocation, rec.Code, rec.Header().Get("Location"))
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	redirectSrv.ErrorsHandler()(rec, httptest.NewRequest(http.MethodGet, "/errors", nil))
+	var errs []seal.Error
+	if err := json.NewDecoder(rec.Body).Decode(&errs); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.URLPath+" "+e.Message)
+	}
+	want := []string{
+		"/ line 6: expected old path, new path and optional status code",
+		`/ line 7: invalid status code "200"`,
+		"/about redirect to /news/x: conflicts with an existing page",
+		"/news/y redirect to /: conflicts with handler directory /news",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected errors %q, got %q", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected errors %q, got %q", want, got)
+		}
+	}
+}
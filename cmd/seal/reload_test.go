@@ -0,0 +1,202 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/wansing/seal"
+	"github.com/wansing/seal/content"
+	"github.com/wansing/seal/handlers/miniblog"
+)
+
+// TestConcurrentReload is meant to be run with the race detector: go test -race
+func TestConcurrentReload(t *testing.T) {
+	myBlog := &miniblog.Miniblog{}
+	raceSrv := &seal.Server{
+		FS: fstest.MapFS{
+			"html.html":                  {Data: []byte(`<main>{{template "main" .}}</main>`)},
+			"main.md":                    {Data: []byte("# Home\n\n{latest}")},
+			"latest.latest":              {},
+			"news.blog/2025-01-01-a.md":  {Data: []byte(`# First post`)},
+			"news.blog/2025-02-01-b.md":  {Data: []byte(`# Second post`)},
+			"static/file.txt":            {Data: []byte(`static`)},
+			"broken/main.html":           {Data: []byte(`{{template "missing" .}}`)},
+			"site/$/main.md":             {Data: []byte(`# Site`)},
+			"site/subsite/main.md":       {Data: []byte(`# Subsite`)},
+			"site/subsite/image.svg":     {Data: []byte(`<svg></svg>`)},
+			"site/subsite/.hidden/x.txt": {Data: []byte(`hidden`)},
+		},
+		Content: map[string]seal.ContentFunc{
+			".html":   content.HTML,
+			".md":     content.Commonmark,
+			".latest": myBlog.Latest,
+		},
+		Handlers: map[string]seal.HandlerGen{
+			".blog": myBlog.MakeHandler,
+		},
+		Publishers: []seal.Publisher{myBlog},
+	}
+	raceSrv.Reload()
+
+	paths := []string{"/", "/news/", "/news/2025-01-01-a", "/static/file.txt", "/site", "/site/subsite", "/broken", "/errors"}
+	errorsHandler := raceSrv.ErrorsHandler()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				raceSrv.Reload()
+			}
+		}()
+	}
+	for _, p := range paths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				rec := httptest.NewRecorder()
+				if p == "/errors" {
+					errorsHandler(rec, httptest.NewRequest(http.MethodGet, p, nil))
+					continue
+				}
+				raceSrv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, p, nil))
+				if p == "/" && !strings.Contains(rec.Body.String(), "First post") {
+					t.Errorf("%s: latest posts missing, got %s", p, rec.Body.String())
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSafeReload(t *testing.T) {
+	safeFS := fstest.MapFS{
+		"html.html": {Data: []byte(`<main>{{template "main" .}}</main>`)},
+		"main.md":   {Data: []byte(`# Good`)},
+	}
+	safeSrv := &seal.Server{
+		FS: safeFS,
+		Content: map[string]seal.ContentFunc{
+			".html": content.HTML,
+			".md":   content.Commonmark,
+		},
+		SafeReload: true,
+	}
+	if err := safeSrv.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	safeFS["main.html"] = &fstest.MapFile{Data: []byte(`{{template "missing" .}}`)}
+	delete(safeFS, "main.md")
+	if err := safeSrv.Reload(); err == nil {
+		t.Fatal("expected reload to be rejected")
+	}
+
+	rec := httptest.NewRecorder()
+	safeSrv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got, want := strings.TrimSpace(rec.Body.String()), `<main><h1 id="good">Good</h1>
+</main>`; got != want {
+		t.Fatalf("expected previous content %s, got %s", want, got)
+	}
+
+	rec = httptest.NewRecorder()
+	safeSrv.ErrorsHandler()(rec, httptest.NewRequest(http.MethodGet, "/errors", nil))
+	for _, want := range []string{`"urlpath": "/"`, `"fspath": "."`, `"severity": "error"`, `"generation": 2`} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Fatalf("expected errors of rejected reload to contain %s, got %s", want, rec.Body.String())
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	safeSrv.ErrorsHandler()(rec, httptest.NewRequest(http.MethodGet, "/errors?format=html", nil))
+	if !strings.Contains(rec.Body.String(), "has been rejected, still serving generation 1") {
+		t.Fatalf("expected html view to report rejection, got %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	safeSrv.ErrorsHandler()(rec, httptest.NewRequest(http.MethodGet, "/errors?prefix=/other", nil))
+	if got := strings.TrimSpace(rec.Body.String()); got != "[]" {
+		t.Fatalf("expected prefix filter to remove all errors, got %s", got)
+	}
+}
+
+func TestErrorLocation(t *testing.T) {
+	locSrv := &seal.Server{
+		FS: fstest.MapFS{
+			"html.html":         {Data: []byte(`{{template "main" .}}`)},
+			"main.html":         {Data: []byte(`Home`)},
+			"parse/main.html":   {Data: []byte("---\ntitle: Parse\n---\n<p>\n{{.URLPath</p>")},
+			"execute/main.html": {Data: []byte("---\ntitle: Execute\n---\n<p>\n  {{template \"missing\" .}}</p>")},
+		},
+		Content: map[string]seal.ContentFunc{
+			".html": content.HTML,
+		},
+	}
+	locSrv.Reload()
+
+	rec := httptest.NewRecorder()
+	locSrv.ErrorsHandler()(rec, httptest.NewRequest(http.MethodGet, "/errors", nil))
+	for _, want := range []string{
+		`"fspath": "parse/main.html",
+		"line": 5,`,
+		`"fspath": "execute",
+		"line": 5,
+		"column": 13,`,
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Fatalf("expected %s, got %s", want, rec.Body.String())
+		}
+	}
+}
+
+func TestPatterns(t *testing.T) {
+	patternSrv := &seal.Server{
+		FS: fstest.MapFS{
+			"html.html":   {Data: []byte(`{{template "main" .}}`)},
+			"main.md":     {Data: []byte(`# Home`)},
+			"404.md":      {Data: []byte(`# Not found`)},
+			"my file.txt": {Data: []byte(`space`)},
+			"a{b}.txt":    {Data: []byte(`braces`)},
+			"de/main.md":  {Data: []byte(`# De`)},
+			"main.de.md":  {Data: []byte(`# Start`)},
+			"main.en.md":  {Data: []byte(`# Home`)},
+		},
+		Content: map[string]seal.ContentFunc{
+			".html": content.HTML,
+			".md":   content.Commonmark,
+		},
+		Languages: []string{"de", "en"},
+	}
+	patternSrv.Reload()
+
+	tests := []struct {
+		method   string
+		input    string
+		wantCode int
+		want     string
+	}{
+		{http.MethodGet, "/my%20file.txt", http.StatusOK, "space"},
+		{http.MethodGet, "/a%7Bb%7D.txt", http.StatusOK, "braces"},
+		{http.MethodPost, "/my%20file.txt", http.StatusMethodNotAllowed, ""},
+	}
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		patternSrv.ServeHTTP(rec, httptest.NewRequest(test.method, test.input, nil))
+		if rec.Code != test.wantCode || !strings.Contains(rec.Body.String(), test.want) {
+			t.Fatalf("%s %s: expected %d %q, got %d %q", test.method, test.input, test.wantCode, test.want, rec.Code, rec.Body.String())
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	patternSrv.ErrorsHandler()(rec, httptest.NewRequest(http.MethodGet, "/errors", nil))
+	if !strings.Contains(rec.Body.String(), `"message": "GET /de is served already"`) { // directory "de" and the language prefix
+		t.Fatalf("expected readable conflict, got %s", rec.Body.String())
+	}
+}
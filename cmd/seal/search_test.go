@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/wansing/seal"
+	"github.com/wansing/seal/content"
+	"github.com/wansing/seal/handlers/miniblog"
+)
+
+func TestSearch(t *testing.T) {
+	myBlog := &miniblog.Miniblog{}
+	searchFS := fstest.MapFS{
+		"html.html":                 {Data: []byte(`<nav>Menu</nav><main>{{template "main" .}}</main>`)},
+		"main.md":                   {Data: []byte("# Home\n\nWelcome to the garden.")},
+		"about/main.md":             {Data: []byte("# About us\n\nWe grow tomatoes and potatoes.")},
+		"search/main.search":        {},
+		"news.blog/index.html":      {Data: []byte(`{{range .Previews}}{{.Title}}{{end}}`)},
+		"news.blog/2025-01-01-a.md": {Data: []byte("# Harvest\n\nThe tomatoes are ripe.")},
+	}
+	searchSrv := &seal.Server{
+		FS: searchFS,
+		Content: map[string]seal.ContentFunc{
+			".html":   content.HTML,
+			".md":     content.Commonmark,
+			".search": seal.Search,
+		},
+		Handlers: map[string]seal.HandlerGen{
+			".blog": myBlog.MakeHandler,
+		},
+		Publishers: []seal.Publisher{myBlog},
+	}
+	searchSrv.Reload()
+
+	search := func(query string) []seal.SearchResult {
+		rec := httptest.NewRecorder()
+		searchSrv.SearchHandler()(rec, httptest.NewRequest(http.MethodGet, "/search.json?q="+query, nil))
+		var results []seal.SearchResult
+		if err := json.NewDecoder(rec.Body).Decode(&results); err != nil {
+			t.Fatal(err)
+		}
+		return results
+	}
+	urlpaths := func(results []seal.SearchResult) string {
+		var paths []string
+		for _, r := range results {
+			paths = append(paths, r.URLPath)
+		}
+		return strings.Join(paths, " ")
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"tomatoes", "/about /news/2025-01-01-a"},
+		{"harvest", "/news/2025-01-01-a"}, // not the blog index page
+		{"tomatoes+ripe", "/news/2025-01-01-a"},
+		{"pota", "/about"}, // prefix
+		{"menu", ""},       // outside of main
+		{"", ""},
+	}
+	for _, test := range tests {
+		if got := urlpaths(search(test.query)); got != test.want {
+			t.Fatalf("%s: expected %q, got %q", test.query, test.want, got)
+		}
+	}
+
+	if results := search("ripe"); len(results) != 1 || results[0].Title != "Harvest" || !strings.HasSuffix(results[0].Snippet, "Harvest The tomatoes are ripe.") {
+		t.Fatalf("unexpected result: %+v", results)
+	}
+
+	rec := httptest.NewRecorder()
+	searchSrv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search?q=potatoes", nil))
+	if body := rec.Body.String(); !strings.Contains(body, `value="potatoes"`) || !strings.Contains(body, `<a href="/about">About us</a>`) {
+		t.Fatalf("search page: unexpected body %s", body)
+	}
+
+	// index is rebuilt on reload
+	delete(searchFS, "about/main.md")
+	searchSrv.Reload()
+	if got := urlpaths(search("potatoes")); got != "" {
+		t.Fatalf("expected no results after reload, got %q", got)
+	}
+	rec = httptest.NewRecorder()
+	searchSrv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search?q=potatoes", nil))
+	if body := rec.Body.String(); !strings.Contains(body, "No results.") {
+		t.Fatalf("search page after reload: unexpected body %s", body)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/wansing/seal"
+	"github.com/wansing/seal/content"
+	"github.com/wansing/seal/handlers/miniblog"
+)
+
+func TestSitemap(t *testing.T) {
+	modTime := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	myBlog := &miniblog.Miniblog{}
+	sitemapFS := fstest.MapFS{
+		"html.html":                 {Data: []byte(`{{template "main" .}}`)},
+		"main.md":                   {Data: []byte(`# Home`), ModTime: modTime},
+		"favicon.ico":               {Data: []byte(`ICON`)},
+		"about/main.md":             {Data: []byte("---\nupdated: 2025-02-03T10:00:00Z\n---\n# About")},
+		"news.blog/2025-01-01-a.md": {Data: []byte(`# First post`)},
+	}
+	sitemapSrv := &seal.Server{
+		FS: sitemapFS,
+		Content: map[string]seal.ContentFunc{
+			".html": content.HTML,
+			".md":   content.Commonmark,
+		},
+		Handlers: map[string]seal.HandlerGen{
+			".blog": myBlog.MakeHandler,
+		},
+		BaseURL: "https://example.org/",
+	}
+	sitemapSrv.Reload()
+
+	get := func(urlpath string) string {
+		rec := httptest.NewRecorder()
+		sitemapSrv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, urlpath, nil))
+		return rec.Body.String()
+	}
+
+	sitemap := get("/sitemap.xml")
+	for _, want := range []string{
+		`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`,
+		"<loc>https://example.org/</loc>\n\t\t<lastmod>2025-04-01T12:00:00Z</lastmod>",
+		"<loc>https://example.org/about</loc>\n\t\t<lastmod>2025-02-03T10:00:00Z</lastmod>",
+		"<loc>https://example.org/news/2025-01-01-a</loc>\n\t\t<lastmod>2025-01-01T00:00:00",
+		"<loc>https://example.org/news/</loc>",
+	} {
+		if !strings.Contains(sitemap, want) {
+			t.Fatalf("sitemap: expected %s, got %s", want, sitemap)
+		}
+	}
+	for _, unwanted := range []string{"favicon.ico", "feed.xml", "robots.txt"} {
+		if strings.Contains(sitemap, unwanted) {
+			t.Fatalf("sitemap: unexpected %s", unwanted)
+		}
+	}
+
+	if got, want := get("/robots.txt"), "Sitemap: https://example.org/sitemap.xml\n"; !strings.HasSuffix(got, want) {
+		t.Fatalf("robots.txt: expected %s, got %s", want, got)
+	}
+
+	// static files are optional, own robots.txt takes precedence
+	sitemapSrv.SitemapStatic = true
+	sitemapFS["robots.txt"] = &fstest.MapFile{Data: []byte("User-agent: *\nDisallow: /\n")}
+	sitemapSrv.Reload()
+	if got := get("/sitemap.xml"); !strings.Contains(got, "<loc>https://example.org/favicon.ico</loc>") || strings.Contains(got, "feed.xml") {
+		t.Fatalf("sitemap: expected static file but no feed, got %s", got)
+	}
+	if got, want := get("/robots.txt"), "User-agent: *\nDisallow: /\n"; got != want {
+		t.Fatalf("robots.txt: expected %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/wansing/seal"
+	"github.com/wansing/seal/content"
+	"github.com/wansing/seal/handlers/miniblog"
+)
+
+func TestMakeSlug(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Hello, World!", "Hello-World"},
+		{"Über uns", "Ueber-uns"},
+		{"Größe", "Groesse"},
+		{"STRASSE ẞ", "STRASSE-SS"},
+		{"Über", "Ueber"}, // decomposed
+		{"Café Crème", "Cafe-Creme"},
+		{"Łódź", "Lodz"},
+		{"Søren Kierkegaard", "Soeren-Kierkegaard"},
+		{"Привет мир", "Привет-мир"},
+		{"東京 2025", "東京-2025"},
+		{"★", "26c57b08"},
+		{"", ""},
+		{" ", ""},
+	}
+	for _, test := range tests {
+		if got := seal.MakeSlug(test.input); got != test.want {
+			t.Fatalf("%q: expected %q, got %q", test.input, test.want, got)
+		}
+	}
+	if seal.MakeSlug("★") == seal.MakeSlug("☆") {
+		t.Fatal("expected distinct slugs")
+	}
+}
+
+func TestSlugCollision(t *testing.T) {
+	myBlog := &miniblog.Miniblog{}
+	collisionSrv := &seal.Server{
+		FS: fstest.MapFS{
+			"html.html":                 {Data: []byte(`{{template "main" .}}`)},
+			"main.md":                   {Data: []byte("# Home")},
+			"Über uns/main.md":          {Data: []byte("# Ueber uns 1")},
+			"Ueber uns/main.md":         {Data: []byte("# Ueber uns 2")},
+			"01-about/main.md":          {Data: []byte("# About 1")},
+			"about/main.md":             {Data: []byte("# About 2")},
+			"news.blog/2025-01-01-a.md": {Data: []byte("# Post")},
+			"news/main.md":              {Data: []byte("# News")},
+			"Привет/main.md":            {Data: []byte("# Privet")},
+		},
+		Content: map[string]seal.ContentFunc{
+			".html": content.HTML,
+			".md":   content.Commonmark,
+		},
+		Handlers: map[string]seal.HandlerGen{
+			".blog": myBlog.MakeHandler,
+		},
+	}
+	collisionSrv.Reload()
+
+	tests := []struct {
+		input    string
+		wantCode int
+		want     string
+	}{
+		{"/01-about", http.StatusNotFound, ""},
+		{"/about", http.StatusOK, "<h1 id=\"about-1\">About 1</h1>\n"}, // sorted first
+		{"/Ueber-uns", http.StatusOK, "<h1 id=\"ueber-uns-2\">Ueber uns 2</h1>\n"},
+		{"/news", http.StatusOK, "<h1 id=\"news\">News</h1>\n"}, // "news" is sorted before "news.blog"
+		{"/%D0%9F%D1%80%D0%B8%D0%B2%D0%B5%D1%82", http.StatusOK, "<h1 id=\"privet\">Privet</h1>\n"},
+	}
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		collisionSrv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.input, nil))
+		if rec.Code != test.wantCode || (test.want != "" && rec.Body.String() != test.want) {
+			t.Fatalf("%s: expected %d %q, got %d %q", test.input, test.wantCode, test.want, rec.Code, rec.Body.String())
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	collisionSrv.ErrorsHandler()(rec, httptest.NewRequest(http.MethodGet, "/errors", nil))
+	var errs []seal.Error
+	if err := json.NewDecoder(rec.Body).Decode(&errs); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.URLPath+" "+e.Message)
+	}
+	want := []string{
+		"/about 01-about and about have the same URL path",
+		"/news news and news.blog have the same URL path",
+		"/Ueber-uns Ueber uns and Über uns have the same URL path",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected errors %q, got %q", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected errors %q, got %q", want, got)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wansing/seal"
+	"github.com/wansing/seal/content"
+)
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "html.html"), []byte(`{{template "main" .}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.md"), []byte("# Before"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	watchSrv := &seal.Server{
+		FS: os.DirFS(dir),
+		Content: map[string]seal.ContentFunc{
+			".html": content.HTML,
+			".md":   content.Commonmark,
+		},
+	}
+	watchSrv.Reload()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watchSrv.Watch(ctx, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond) // let Watch take its initial fingerprint
+
+	if err := os.WriteFile(filepath.Join(dir, "main.md"), []byte("# After the change"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `<h1 id="after-the-change">After the change</h1>`
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		rec := httptest.NewRecorder()
+		watchSrv.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+		if strings.TrimSpace(rec.Body.String()) == want {
+			return
+		}
+	}
+	rec := httptest.NewRecorder()
+	watchSrv.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	t.Fatalf("watch did not reload, want %s, got %s", want, rec.Body.String())
+}
@@ -0,0 +1,164 @@
+package content
+
+import (
+	"cmp"
+	"html/template"
+	"net/url"
+	"time"
+
+	"github.com/wansing/go-ical-cache"
+	"github.com/wansing/seal/content/calendar"
+)
+
+// CalendarAgenda renders the upcoming events of an iCalendar feed, grouped by day, e.g. for a sidebar or a homepage.
+//
+// Like for CalendarBS5, the file content is the URL of the feed, preceded by optional "key: value" lines:
+// "lang", "timezone", "template", "limit" (number of events) and "days" (number of days, instead of the limit).
+// The agenda begins now, or on the date in the query parameter "from". Events are shown with their times, location and description.
+type CalendarAgenda struct {
+	Config   icalcache.Config // default url: file content
+	Lang     string           // language of the labels, default: language of the page
+	Location *time.Location   // default: time.Local
+	Limit    int              // default: 10, unless Days is set
+	Days     int
+	Template string // replaces the built-in markup, gets an AgendaView
+}
+
+// agendaTemplate is the name of the built-in template. A content file of that name, like "calendar-agenda.html", overrides it.
+const agendaTemplate = "calendar-agenda"
+
+// An AgendaView is passed to the agenda template.
+type AgendaView struct {
+	calendar.Agenda
+	CalendarView
+	Prev  time.Time // begin of the previous page, zero if unknown
+	Paged bool      // the agenda does not begin now
+}
+
+// Link returns the URL of the page with the agenda beginning on the day of from.
+func (view AgendaView) Link(from time.Time) string {
+	return view.link("agenda", from)
+}
+
+// FirstLink returns the URL of the page with the agenda beginning now.
+func (view AgendaView) FirstLink() string {
+	return view.link("agenda", time.Time{})
+}
+
+type agendaData struct {
+	calendarData
+	Limit int
+	Days  int
+}
+
+// Agenda returns the agenda for the query parameter "from". The language lang of the page is used unless data.Lang is set.
+func (data agendaData) Agenda(requestURL *url.URL, lang string) AgendaView {
+	events, err := data.Feed.Get(data.Location)
+
+	var view = AgendaView{
+		CalendarView: data.view(requestURL, lang, err), // don't return err, don't interrupt template execution
+	}
+
+	var begin = time.Now().In(data.Location)
+	if from, err := time.ParseInLocation(time.DateOnly, requestURL.Query().Get("from"), data.Location); err == nil {
+		begin = from
+		view.Paged = true
+	}
+	var end time.Time
+	if data.Days > 0 {
+		day := time.Date(begin.Year(), begin.Month(), begin.Day(), 0, 0, 0, 0, data.Location)
+		end = day.AddDate(0, 0, data.Days)
+		view.Prev = day.AddDate(0, 0, -data.Days)
+	}
+
+	view.Agenda = calendar.MakeAgenda(events, begin, end, data.Limit, data.Location)
+	if data.Days > 0 {
+		view.Next = end
+	}
+	return view
+}
+
+func (cal CalendarAgenda) Make(t *template.Template, urlpath, fileroot string, filecontent []byte) error {
+	calData, header, err := calendarSettings(filecontent, fileroot, cal.Config, cal.Lang, nil, cal.Location)
+	if err != nil {
+		return err
+	}
+
+	var data = agendaData{
+		calendarData: calData,
+		Limit:        cal.Limit,
+		Days:         cal.Days,
+	}
+	if n, ok := header.Int("limit"); ok {
+		data.Limit, data.Days = n, 0
+	}
+	if n, ok := header.Int("days"); ok {
+		data.Days, data.Limit = n, 0
+	}
+	if data.Limit <= 0 && data.Days <= 0 {
+		data.Limit = 10
+	}
+
+	name, err := calendarTemplateName(t, header, "template", agendaTemplate, cmp.Or(cal.Template, calendarAgendaTemplate))
+	if err != nil {
+		return err
+	}
+
+	MarkDynamic(t)
+	return ParseWithData(
+		t,
+		`{{with .Agenda $.RequestURL $.Lang}}{{template "`+name+`" .}}{{end}}`,
+		func() agendaData {
+			return data
+		},
+	)
+}
+
+const calendarAgendaTemplate = `<div>
+	{{with .Error}}
+		<div class="alert alert-danger">{{$.T "Error getting calendar events"}}: {{.}}</div>
+	{{end}}
+	{{range .Days}}
+		<h3 class="h5 mt-3">{{$.DayName .Begin}}</h3>
+		<ul class="list-unstyled">
+			{{range .Events}}
+				<li>
+					<span class="text-muted">
+						{{if .AllDay}}
+							{{if .Multiday}}{{$.T "until"}} {{$.DayName .LastDay}}{{end}}
+						{{else}}
+							{{$.Time .Start}}&ndash;{{if .Multiday}}{{$.DayName .LastDay}}, {{end}}{{$.Time .End}}
+						{{end}}
+					</span>
+					{{with .URL}}
+						<a href="{{.}}">
+					{{end}}
+					{{.Summary}}
+					{{if .URL}}
+						</a>
+					{{end}}
+					{{with $.Details .Event}}
+						{{with .Location}}
+							<div class="small text-muted">{{.}}</div>
+						{{end}}
+						{{with .Description}}
+							<div class="small">{{.}}</div>
+						{{end}}
+					{{end}}
+				</li>
+			{{end}}
+		</ul>
+	{{else}}
+		<p>{{$.T "No upcoming events."}}</p>
+	{{end}}
+	<div class="d-flex justify-content-between">
+		{{if not .Prev.IsZero}}
+			<a href="{{$.Link .Prev}}">&#9668; {{$.T "Earlier"}}</a>
+		{{else if .Paged}}
+			<a href="{{$.FirstLink}}">&#9668; {{$.T "Upcoming events"}}</a>
+		{{end}}
+		{{if not .Next.IsZero}}
+			<a href="{{$.Link .Next}}">{{$.T "Later"}} &#9658;</a>
+		{{end}}
+	</div>
+</div>`
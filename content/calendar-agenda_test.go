@@ -0,0 +1,181 @@
+package content
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wansing/go-ical-cache"
+	"github.com/wansing/seal/content/calendar"
+)
+
+func TestMakeAgenda(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	at := func(day, hour int) time.Time {
+		return time.Date(2025, time.March, day, hour, 0, 0, 0, loc)
+	}
+	events := []icalcache.Event{
+		{Start: at(5, 10), End: at(5, 11), Summary: "c"},
+		{Start: at(3, 9), End: at(3, 10), Summary: "past"},
+		{Start: at(4, 0), End: at(6, 0), Summary: "b"}, // all-day, two days, has begun
+		{Start: at(5, 9), End: at(5, 10), Summary: "a"},
+		{Start: at(7, 20), End: at(8, 2), Summary: "d"}, // crosses midnight
+		{Start: at(9, 0).UTC(), End: at(10, 0).UTC(), Summary: "e"},
+	}
+
+	agenda := calendar.MakeAgenda(events, at(4, 12), time.Time{}, 0, loc)
+	var got []string
+	for _, day := range agenda.Days {
+		var summaries []string
+		for _, event := range day.Events {
+			summaries = append(summaries, event.Summary)
+		}
+		got = append(got, day.Begin.Format("02")+":"+strings.Join(summaries, ","))
+	}
+	if want := "04:b 05:a,c 07:d 09:e"; strings.Join(got, " ") != want {
+		t.Fatalf("got %v, want %s", got, want)
+	}
+	if b := agenda.Days[0].Events[0]; !b.AllDay || !b.Multiday || b.LastDay().Day() != 5 {
+		t.Fatalf("got %+v", b)
+	}
+	if d := agenda.Days[2].Events[0]; d.AllDay || !d.Multiday || d.LastDay().Day() != 8 {
+		t.Fatalf("got %+v", d)
+	}
+	if e := agenda.Days[3].Events[0]; !e.AllDay || e.Multiday || e.Start.Location() != loc {
+		t.Fatalf("got %+v", e)
+	}
+	if !agenda.Next.IsZero() {
+		t.Fatalf("got next %v", agenda.Next)
+	}
+
+	// limit cuts at a day boundary
+	agenda = calendar.MakeAgenda(events, at(4, 12), time.Time{}, 2, loc)
+	if len(agenda.Days) != 1 || !agenda.Next.Equal(at(5, 0)) {
+		t.Fatalf("got %d days, next %v", len(agenda.Days), agenda.Next)
+	}
+	agenda = calendar.MakeAgenda(events, at(4, 12), time.Time{}, 1, loc)
+	if len(agenda.Days) != 1 || !agenda.Next.Equal(at(5, 0)) {
+		t.Fatalf("got %d days, next %v", len(agenda.Days), agenda.Next)
+	}
+
+	// date range
+	agenda = calendar.MakeAgenda(events, at(5, 0), at(8, 0), 0, loc)
+	if len(agenda.Days) != 2 || agenda.Days[0].Events[0].Summary != "b" || agenda.Days[1].Events[0].Summary != "d" {
+		t.Fatalf("got %+v", agenda.Days)
+	}
+}
+
+func TestCalendarAgenda(t *testing.T) {
+	tests := []struct {
+		filecontent string
+		cal         CalendarAgenda
+		query       string
+		lang        string // of the page
+		raw         bool   // compare the markup instead of the text
+		want        []string
+	}{
+		{"", CalendarAgenda{}, "", "en", false, []string{"No upcoming events."}},
+		{"", CalendarAgenda{}, "", "", false, []string{"Keine anstehenden Termine."}}, // default
+		{"", CalendarAgenda{}, "", "de", false, []string{"Keine anstehenden Termine."}},
+		{"lang: en", CalendarAgenda{Lang: "de"}, "from=2025-03-01", "de", false, []string{"No upcoming events.", "Upcoming events"}},
+		{"days: 7", CalendarAgenda{}, "from=2025-03-08", "", true, []string{`href="/?from=2025-03-01#cal"`, `href="/?from=2025-03-15#cal"`}},
+		{"", CalendarAgenda{Template: `{{.Begin.Format "2006-01-02"}} {{.Paged}}`}, "from=2025-03-08", "", false, []string{"2025-03-08 true"}},
+	}
+	for _, test := range tests {
+		tmpl := template.New("main")
+		if err := test.cal.Make(tmpl, "/", "cal", []byte(test.filecontent)); err != nil {
+			t.Fatal(err)
+		}
+		got := renderCalendar(t, tmpl, test.query, test.lang, test.raw)
+		for _, want := range test.want {
+			if !strings.Contains(got, want) {
+				t.Fatalf("%q: expected %q in %q", test.filecontent, want, got)
+			}
+		}
+	}
+
+	// invalid header
+	if err := (CalendarAgenda{}).Make(template.New("main"), "/", "cal", []byte("timezone: Nowhere/Nothing\nhttps://example.com/cal.ics")); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestCalendarAgendaDetails(t *testing.T) {
+	const ics = "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"PRODID:test\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:1\r\n" +
+		"DTSTAMP:20250101T000000Z\r\n" +
+		"DTSTART:20250305T180000Z\r\n" +
+		"DTEND:20250305T200000Z\r\n" +
+		"SUMMARY:Meeting\r\n" +
+		"LOCATION:Town hall\r\n" +
+		"DESCRIPTION:Bring a friend\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-Modified-Since") != "" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Last-Modified", "Wed, 01 Jan 2025 00:00:00 GMT")
+		w.Write([]byte(ics))
+	}))
+	defer ts.Close()
+
+	tmpl := template.New("main")
+	if err := (CalendarAgenda{Location: time.UTC}).Make(tmpl, "/", "cal", []byte(ts.URL)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := renderCalendar(t, tmpl, "from=2025-03-01", "en", false), "18:00&ndash;20:00 Meeting Town hall Bring a friend"; !strings.Contains(got, want) {
+		t.Fatalf("expected %q in %q", want, got)
+	}
+	if got := renderCalendar(t, tmpl, "from=2025-03-01", "en", false); !strings.Contains(got, "Town hall") || requests != 1 {
+		t.Fatalf("expected cached events after %d requests, got %q", requests, got)
+	}
+
+	// not modified
+	feed := &calendarFeed{
+		Config:   icalcache.Config{URL: ts.URL},
+		Interval: time.Nanosecond,
+	}
+	for range 2 {
+		events, err := feed.Get(time.UTC)
+		if err != nil || len(events) != 1 || feed.Details(events[0]).Location != "Town hall" {
+			t.Fatalf("got %v %v", events, err)
+		}
+	}
+	if requests != 3 {
+		t.Fatalf("expected 3 requests, got %d", requests)
+	}
+}
+
+func TestCalendarFeedBackoff(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	feed := &calendarFeed{
+		Config: icalcache.Config{URL: ts.URL},
+	}
+	for range 2 {
+		if _, err := feed.Get(time.UTC); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+	if requests != 1 {
+		t.Fatalf("expected 1 request after an error, got %d", requests)
+	}
+}
@@ -1,136 +1,364 @@
 package content
 
 import (
+	"bufio"
+	"cmp"
+	"fmt"
 	"html/template"
 	"net/url"
+	"regexp"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/wansing/go-ical-cache"
 	"github.com/wansing/seal/content/calendar"
 )
 
+// CalendarBS5 renders a month of an iCalendar feed, using Bootstrap 5 classes.
+//
+// The file content is the URL of the feed. It can be preceded by "key: value" lines, which override the fields:
+// "lang", "first-weekday" (like "sunday"), "timezone" (like "Europe/Berlin"), "view" (like "week"),
+// "template" and "schedule-template" (names of templates in the content tree which replace the markup).
+// The query parameter "view" overrides the view.
 type CalendarBS5 struct {
-	Config icalcache.Config // default url: file content
+	Config           icalcache.Config // default url: file content
+	Lang             string           // language of the labels, default: language of the page
+	FirstWeekday     *time.Weekday    // default: Monday
+	Location         *time.Location   // default: time.Local
+	View             string           // "month" (default), "week" or "day"
+	Template         string           // replaces the built-in markup of the month view, gets a MonthView
+	ScheduleTemplate string           // replaces the built-in markup of the week and day views, gets a ScheduleView
 }
 
-type monthView struct {
-	calendar.Month
+// calendarTemplate and scheduleTemplate are the names of the built-in templates. A content file of that name, like "calendar-bs5.html", overrides it.
+const (
+	calendarTemplate = "calendar-bs5"
+	scheduleTemplate = "calendar-bs5-schedule"
+)
+
+// views are the values of the "view" header line and query parameter.
+var views = []string{"month", "week", "day"}
+
+// A CalendarView is embedded in the views which are passed to the calendar templates. It provides the labels and links.
+type CalendarView struct {
 	Error error
+	Lang  string
+
+	data       calendarData
+	requestURL *url.URL
+}
+
+// T translates msg into view.Lang, see Message.
+func (view CalendarView) T(msg string) string {
+	return Message(view.Lang, msg)
+}
+
+// MonthName returns the name of the month in view.Lang.
+func (view CalendarView) MonthName(month time.Month) string {
+	return Message(view.Lang, month.String())
+}
+
+// WeekdayName returns the abbreviated name of the weekday in view.Lang.
+func (view CalendarView) WeekdayName(day time.Weekday) string {
+	return Message(view.Lang, day.String()[:3])
+}
+
+// DayName returns the weekday, month and day of t in view.Lang, like "Monday, March 3".
+func (view CalendarView) DayName(t time.Time) string {
+	return strings.NewReplacer(
+		"{weekday}", Message(view.Lang, t.Weekday().String()),
+		"{month}", Message(view.Lang, t.Month().String()),
+		"{day}", strconv.Itoa(t.Day()),
+	).Replace(Message(view.Lang, "{weekday}, {month} {day}"))
+}
+
+// Time returns the time of day of t in view.Lang, like "15:04".
+func (view CalendarView) Time(t time.Time) string {
+	return t.Format(Message(view.Lang, "15:04"))
+}
+
+// Hour returns the full hour in view.Lang, like "15:00".
+func (view CalendarView) Hour(hour int) string {
+	return view.Time(time.Date(2000, time.January, 1, hour, 0, 0, 0, time.UTC))
+}
+
+// Details returns the location and description of the event.
+func (view CalendarView) Details(event icalcache.Event) EventDetails {
+	return view.data.Feed.Details(event)
+}
+
+// link returns the URL of the page with the given view of the day of date, see calendarData.Link.
+func (view CalendarView) link(name string, date time.Time) string {
+	return view.data.Link(view.requestURL, name, date)
+}
+
+// A MonthView is passed to the calendar template.
+type MonthView struct {
+	calendar.Month
+	CalendarView
+}
+
+// Link returns the URL of the page with the given month.
+func (view MonthView) Link(month calendar.Month) string {
+	return view.link("month", time.Date(month.Year, month.Month, 1, 0, 0, 0, 0, time.UTC))
+}
+
+// DayLink returns the URL of the page with the day view of the given day.
+func (view MonthView) DayLink(day calendar.Day) string {
+	return view.link("day", day.Begin)
+}
+
+// ViewLink returns the URL of the page with the given view of the first day of the month.
+func (view MonthView) ViewLink(name string) string {
+	return view.link(name, time.Date(view.Year, view.Month.Month, 1, 0, 0, 0, 0, time.UTC))
 }
 
 type calendarData struct {
-	Feed     *icalcache.Cache
-	Fileroot string
+	Feed         *calendarFeed
+	Fileroot     string
+	Lang         string
+	FirstWeekday time.Weekday
+	Location     *time.Location
+	View         string
 }
 
-func (data calendarData) Link(requestURL *url.URL, month calendar.Month) string {
+// ViewName returns the query parameter "view" if it is valid, else data.View.
+func (data calendarData) ViewName(requestURL *url.URL) string {
+	if name := requestURL.Query().Get("view"); slices.Contains(views, name) {
+		return name
+	}
+	return data.View
+}
+
+// Link returns requestURL with the query parameters for the given view of the day of date, and with data.Fileroot as anchor.
+// The view "agenda" begins on date, or now if date is zero.
+func (data calendarData) Link(requestURL *url.URL, name string, date time.Time) string {
 	var u = *requestURL // copy
 	link := u.Query()
-	link.Set("year", strconv.Itoa(month.Year))
-	link.Set("month", strconv.Itoa(int(month.Month)))
+	switch name {
+	case "agenda":
+		if date.IsZero() {
+			link.Del("from")
+		} else {
+			link.Set("from", date.Format(time.DateOnly))
+		}
+	case "month":
+		link.Set("view", name)
+		link.Set("year", strconv.Itoa(date.Year()))
+		link.Set("month", strconv.Itoa(int(date.Month())))
+		link.Del("date")
+	default:
+		link.Set("view", name)
+		link.Set("date", date.Format(time.DateOnly))
+		link.Del("year")
+		link.Del("month")
+	}
 	u.RawQuery = link.Encode()
 	u.Fragment = data.Fileroot // anchor
 	return u.String()
 }
 
-func (data calendarData) Month(requestURL *url.URL) monthView {
+// view returns the CalendarView for the language lang of the page.
+func (data calendarData) view(requestURL *url.URL, lang string, err error) CalendarView {
+	return CalendarView{
+		Error:      err,
+		Lang:       data.lang(lang),
+		data:       data,
+		requestURL: requestURL,
+	}
+}
+
+// DefaultCalendarLang is the language of the calendar labels if neither the calendar nor the page has a language.
+// It is German, because the labels were German before they could be translated.
+const DefaultCalendarLang = "de"
+
+// lang returns data.Lang, else the language pageLang of the page, else DefaultCalendarLang.
+func (data calendarData) lang(pageLang string) string {
+	switch {
+	case data.Lang != "":
+		return data.Lang
+	case pageLang != "":
+		return pageLang
+	default:
+		return DefaultCalendarLang
+	}
+}
+
+// Month returns the month view for the query parameters "year" and "month". The language lang of the page is used unless data.Lang is set.
+func (data calendarData) Month(requestURL *url.URL, lang string) MonthView {
 	year, _ := strconv.Atoi(requestURL.Query().Get("year"))
 	month, _ := strconv.Atoi(requestURL.Query().Get("month"))
-	events, err := data.Feed.Get(time.Local)
-	return monthView{
-		Month: calendar.MakeMonth(events, year, month),
-		Error: err,
-	} // don't return err, don't interrupt template execution
-}
-
-func (calendarData) MonthName(month time.Month) string {
-	switch month {
-	case time.January:
-		return "Januar"
-	case time.February:
-		return "Februar"
-	case time.March:
-		return "März"
-	case time.April:
-		return "April"
-	case time.May:
-		return "Mai"
-	case time.June:
-		return "Juni"
-	case time.July:
-		return "Juli"
-	case time.August:
-		return "August"
-	case time.September:
-		return "September"
-	case time.October:
-		return "Oktober"
-	case time.November:
-		return "November"
-	case time.December:
-		return "Dezember"
-	default:
-		return ""
+	events, err := data.Feed.Get(data.Location)
+	return MonthView{
+		Month:        calendar.MakeMonthIn(events, year, month, data.FirstWeekday, data.Location),
+		CalendarView: data.view(requestURL, lang, err), // don't return err, don't interrupt template execution
 	}
 }
 
-func (cal CalendarBS5) Make(t *template.Template, urlpath, fileroot string, filecontent []byte) error {
-	var config = cal.Config
+var calendarHeader = regexp.MustCompile(`^([a-z-]+):\s+(.*)$`)
+
+// parseCalendarFile splits the file content into the header lines and the URL.
+func parseCalendarFile(filecontent []byte) (Meta, string, error) {
+	var header []string
+	var feedURL string
+	scanner := bufio.NewScanner(strings.NewReader(string(filecontent)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case calendarHeader.MatchString(line): // does not match "https://"
+			header = append(header, line)
+		default:
+			feedURL = line
+		}
+	}
+	meta, err := ParseMeta([]byte(strings.Join(header, "\n")))
+	return meta, feedURL, err
+}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(s, day.String()) {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown weekday %q", s)
+}
+
+// calendarSettings returns the data of a calendar content file. The header lines of filecontent override the given defaults.
+func calendarSettings(filecontent []byte, fileroot string, config icalcache.Config, lang string, firstWeekday *time.Weekday, loc *time.Location) (calendarData, Meta, error) {
+	header, feedURL, err := parseCalendarFile(filecontent)
+	if err != nil {
+		return calendarData{}, nil, err
+	}
+
 	if config == (icalcache.Config{}) {
 		config = icalcache.Config{
-			URL: string(filecontent),
+			URL: feedURL,
+		}
+	}
+
+	var data = calendarData{
+		Feed:         &calendarFeed{Config: config}, // shared by all requests
+		Fileroot:     fileroot,
+		Lang:         lang,
+		FirstWeekday: time.Monday,
+		Location:     time.Local,
+		View:         "month",
+	}
+	if firstWeekday != nil {
+		data.FirstWeekday = *firstWeekday
+	}
+	if loc != nil {
+		data.Location = loc
+	}
+	if lang := header.String("lang"); lang != "" {
+		data.Lang = lang
+	}
+	if s := header.String("first-weekday"); s != "" {
+		if data.FirstWeekday, err = parseWeekday(s); err != nil {
+			return calendarData{}, nil, err
+		}
+	}
+	if s := header.String("timezone"); s != "" {
+		if data.Location, err = time.LoadLocation(s); err != nil {
+			return calendarData{}, nil, err
+		}
+	}
+	if s := header.String("view"); s != "" {
+		if !slices.Contains(views, s) {
+			return calendarData{}, nil, fmt.Errorf("unknown view %q", s)
+		}
+		data.View = s
+	}
+	return data, header, nil
+}
+
+// calendarTemplateName returns the header value of key, or defaultName. In the latter case, it defines defaultName as text, unless a template of that name exists already.
+func calendarTemplateName(t *template.Template, header Meta, key, defaultName, text string) (string, error) {
+	if name := header.String(key); name != "" {
+		return name, nil
+	}
+	if t.Lookup(defaultName) == nil { // else it is defined in the content tree or by a previous calendar
+		if _, err := t.New(defaultName).Parse(text); err != nil {
+			return "", err
+		}
+	}
+	return defaultName, nil
+}
+
+func (cal CalendarBS5) Make(t *template.Template, urlpath, fileroot string, filecontent []byte) error {
+	data, header, err := calendarSettings(filecontent, fileroot, cal.Config, cal.Lang, cal.FirstWeekday, cal.Location)
+	if err != nil {
+		return err
+	}
+	if cal.View != "" && header.String("view") == "" {
+		if !slices.Contains(views, cal.View) {
+			return fmt.Errorf("unknown view %q", cal.View)
 		}
+		data.View = cal.View
+	}
+	name, err := calendarTemplateName(t, header, "template", calendarTemplate, cmp.Or(cal.Template, calendarBS5Template))
+	if err != nil {
+		return err
+	}
+	scheduleName, err := calendarTemplateName(t, header, "schedule-template", scheduleTemplate, cmp.Or(cal.ScheduleTemplate, calendarBS5ScheduleTemplate))
+	if err != nil {
+		return err
 	}
 
+	MarkDynamic(t)
 	return ParseWithData(
 		t,
-		`{{$data := .}}
-		{{with .Month $.RequestURL}}
-			<div>
-				{{with .Error}}
-					<div class="alert alert-danger text-center">Error getting calendar events: {{.}}</div>
-				{{end}}
-				<div class="p-2 d-flex justify-content-center align-items-center">
-					<a class="btn btn-success" href="{{$data.Link $.RequestURL .Prev}}">&#9668;</a>
-					<strong class="h3 mx-3 my-0">{{$data.MonthName .Month.Month}} {{.Year}}</strong>
-					<a class="btn btn-success" href="{{$data.Link $.RequestURL .Next}}">&#9658;</a>
-				</div>
-				<div style="display: grid; grid-template-columns: repeat(7, 1fr);">
-					<div class="p-2 text-center"><strong>Mo</strong></div>
-					<div class="p-2 text-center"><strong>Di</strong></div>
-					<div class="p-2 text-center"><strong>Mi</strong></div>
-					<div class="p-2 text-center"><strong>Do</strong></div>
-					<div class="p-2 text-center"><strong>Fr</strong></div>
-					<div class="p-2 text-center"><strong>Sa</strong></div>
-					<div class="p-2 text-center"><strong>So</strong></div>
-				</div>
-				<div class="border-bottom border-dark" style="display: grid; grid-template-columns: repeat(7, 1fr);">
-					{{range .Weeks}}
-						{{$week := .}}
-						{{range .Days}}
-							<div class="p-2 text-center border-top border-dark" style="grid-column-start: calc({{.NumInWeek}} + 1);">{{.Number}}</div>
-						{{end}}
-						{{range .Events}}
-							<div class="p-2 bg-success bg-opacity-25" style="grid-column-start: calc({{$week.NumInWeekBegin .}} + 1); grid-column-end: calc({{$week.NumInWeekEnd .}} + 2);">
-								{{with .URL}}
-									<a href="{{.}}">
-								{{end}}
-								{{.Summary}}
-								{{if .URL}}
-									</a>
-								{{end}}
-							</div>
-						{{end}}
-					{{end}}
-				</div>
-			</div>
-		{{end}}`,
+		`{{if eq (.ViewName $.RequestURL) "month"}}`+
+			`{{with .Month $.RequestURL $.Lang}}{{template "`+name+`" .}}{{end}}`+
+			`{{else}}`+
+			`{{with .Schedule $.RequestURL $.Lang}}{{template "`+scheduleName+`" .}}{{end}}`+
+			`{{end}}`,
 		func() calendarData {
-			return calendarData{
-				Feed:     &icalcache.Cache{Config: config},
-				Fileroot: fileroot,
-			}
+			return data
 		},
 	)
 }
+
+const calendarBS5Template = `<div>
+	{{with .Error}}
+		<div class="alert alert-danger text-center">{{$.T "Error getting calendar events"}}: {{.}}</div>
+	{{end}}
+	<div class="p-2 d-flex justify-content-center align-items-center">
+		<a class="btn btn-success" href="{{$.Link .Prev}}">&#9668;</a>
+		<strong class="h3 mx-3 my-0">{{$.MonthName .Month.Month}} {{.Year}}</strong>
+		<a class="btn btn-success" href="{{$.Link .Next}}">&#9658;</a>
+		<div class="btn-group btn-group-sm ms-3">
+			<a class="btn btn-outline-success active" href="{{$.ViewLink "month"}}">{{$.T "Month"}}</a>
+			<a class="btn btn-outline-success" href="{{$.ViewLink "week"}}">{{$.T "Week"}}</a>
+			<a class="btn btn-outline-success" href="{{$.ViewLink "day"}}">{{$.T "Day"}}</a>
+		</div>
+	</div>
+	<div style="display: grid; grid-template-columns: repeat(7, 1fr);">
+		{{range .Weekdays}}
+			<div class="p-2 text-center"><strong>{{$.WeekdayName .}}</strong></div>
+		{{end}}
+	</div>
+	<div class="border-bottom border-dark" style="display: grid; grid-template-columns: repeat(7, 1fr);">
+		{{range .Weeks}}
+			{{$week := .}}
+			{{range .Days}}
+				<div class="p-2 text-center border-top border-dark" style="grid-column-start: calc({{.NumInWeek}} + 1);"><a class="text-reset text-decoration-none" href="{{$.DayLink .}}">{{.Number}}</a></div>
+			{{end}}
+			{{range .Events}}
+				<div class="p-2 bg-success bg-opacity-25" style="grid-column-start: calc({{$week.NumInWeekBegin .}} + 1); grid-column-end: calc({{$week.NumInWeekEnd .}} + 2);">
+					{{with .URL}}
+						<a href="{{.}}">
+					{{end}}
+					{{.Summary}}
+					{{if .URL}}
+						</a>
+					{{end}}
+				</div>
+			{{end}}
+		{{end}}
+	</div>
+</div>`
@@ -0,0 +1,85 @@
+package content
+
+import (
+	"bytes"
+	"html/template"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+type calendarTestData struct {
+	RequestURL *url.URL
+	Lang       string
+}
+
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// renderCalendar executes tmpl for a page with the given query and language. Unless raw, it replaces tags by spaces and collapses whitespace.
+func renderCalendar(t *testing.T, tmpl *template.Template, query, lang string, raw bool) string {
+	t.Helper()
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, calendarTestData{
+		RequestURL: &url.URL{Path: "/", RawQuery: query},
+		Lang:       lang,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw {
+		return buf.String()
+	}
+	return strings.Join(strings.Fields(tagPattern.ReplaceAllString(buf.String(), " ")), " ")
+}
+
+func TestCalendarBS5(t *testing.T) {
+	tests := []struct {
+		filecontent string
+		cal         CalendarBS5
+		override    string // template in the content tree
+		lang        string // of the page
+		raw         bool   // compare the markup instead of the text
+		want        []string
+	}{
+		{"", CalendarBS5{}, "", "en", false, []string{"March 2025", "Mon Tue Wed Thu Fri Sat Sun"}},
+		{"", CalendarBS5{}, "", "de", false, []string{"März 2025", "Mo Di Mi Do Fr Sa So"}},
+		{"", CalendarBS5{}, "", "", false, []string{"März 2025", "Mo Di Mi Do Fr Sa So"}}, // default
+		{"lang: en\nfirst-weekday: sunday\ntimezone: America/New_York\n", CalendarBS5{Lang: "de"}, "", "de", false, []string{"March 2025", "Sun Mon Tue Wed Thu Fri Sat"}},
+		{"", CalendarBS5{Template: `{{.Year}}-{{.Month.Month}}`}, "", "", false, []string{"2025-March"}},
+		{"", CalendarBS5{}, `custom {{.MonthName .Month.Month}}`, "de", false, []string{"custom März"}},
+		{"template: my-calendar", CalendarBS5{}, `mine {{.Year}}`, "", false, []string{"mine 2025"}},
+		{"", CalendarBS5{}, "", "", true, []string{`href="/?month=2&amp;view=month&amp;year=2025#cal"`, `href="/?month=4&amp;view=month&amp;year=2025#cal"`}},
+	}
+	for _, test := range tests {
+		tmpl := template.New("main")
+		if test.override != "" {
+			name := "calendar-bs5"
+			if strings.HasPrefix(test.filecontent, "template:") {
+				name = "my-calendar"
+			}
+			template.Must(tmpl.New(name).Parse(test.override))
+		}
+		if err := test.cal.Make(tmpl, "/", "cal", []byte(test.filecontent)); err != nil {
+			t.Fatal(err)
+		}
+		got := renderCalendar(t, tmpl, "year=2025&month=3", test.lang, test.raw)
+		for _, want := range test.want {
+			if !strings.Contains(got, want) {
+				t.Fatalf("%q: expected %q in %q", test.filecontent, want, got)
+			}
+		}
+	}
+
+	// invalid header
+	if err := (CalendarBS5{}).Make(template.New("main"), "/", "cal", []byte("first-weekday: someday\nhttps://example.com/cal.ics")); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestParseCalendarFile(t *testing.T) {
+	header, feedURL, err := parseCalendarFile([]byte("timezone: Europe/Berlin\n\nhttps://example.com/cal.ics\n"))
+	if err != nil || header.String("timezone") != "Europe/Berlin" || feedURL != "https://example.com/cal.ics" {
+		t.Fatalf("got %v %q %v", header, feedURL, err)
+	}
+}
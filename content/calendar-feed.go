@@ -0,0 +1,201 @@
+package content
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/emersion/go-ical"
+	"github.com/wansing/go-ical-cache"
+)
+
+// EventDetails holds the properties of an event which icalcache.Event lacks.
+type EventDetails struct {
+	Location    string
+	Description string
+}
+
+type eventKey struct {
+	uid   string
+	start int64 // unix time, as recurrences share the uid
+}
+
+func keyOf(event icalcache.Event) eventKey {
+	return eventKey{event.UID, event.Start.Unix()}
+}
+
+// feedClients are shared by all calendar feeds, so idle connections are reused. The second one skips TLS verification.
+var feedClients = [2]*http.Client{
+	{
+		Timeout: 5 * time.Second,
+	},
+	{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	},
+}
+
+// A calendarFeed caches the events of an iCalendar feed like icalcache.Cache, and additionally their EventDetails, which icalcache.Event lacks.
+type calendarFeed struct {
+	Config   icalcache.Config
+	Interval time.Duration // default is two minutes, also after errors
+
+	lock         sync.RWMutex // write lock is held while fetching, concurrent calls wait for the result
+	lastChecked  time.Time
+	lastModified string // Last-Modified header of the response, sent as If-Modified-Since
+	events       []icalcache.Event
+	details      map[eventKey]EventDetails
+	err          error // of the last fetch
+}
+
+// Get returns all events. The defaultLocation is used if the feed contains no TZID location.
+// If the last fetch has failed, the error is returned along with the events of the last successful fetch.
+func (feed *calendarFeed) Get(defaultLocation *time.Location) ([]icalcache.Event, error) {
+	if feed.Config.URL == "" {
+		return nil, nil
+	}
+
+	var interval = feed.Interval
+	if interval <= 0 {
+		interval = 2 * time.Minute
+	}
+
+	feed.lock.RLock()
+	var fresh = time.Since(feed.lastChecked) < interval
+	events, err := feed.events, feed.err
+	feed.lock.RUnlock()
+	if fresh {
+		return events, err
+	}
+
+	// first call takes the write lock and fetches, subsequent calls wait until it has finished
+	if !feed.lock.TryLock() {
+		feed.lock.RLock()
+		defer feed.lock.RUnlock()
+		return feed.events, feed.err
+	}
+	defer feed.lock.Unlock()
+	if time.Since(feed.lastChecked) < interval { // fetched meanwhile
+		return feed.events, feed.err
+	}
+
+	events, details, err := feed.fetch(defaultLocation)
+	if err == nil && (events != nil || details != nil) {
+		feed.events, feed.details = events, details
+	}
+	feed.err = err
+	feed.lastChecked = time.Now() // back off after errors too
+	return feed.events, feed.err
+}
+
+// Details returns the location and description of event, which must have been returned by Get.
+func (feed *calendarFeed) Details(event icalcache.Event) EventDetails {
+	feed.lock.RLock()
+	defer feed.lock.RUnlock()
+	return feed.details[keyOf(event)]
+}
+
+// fetch downloads and decodes the feed. It returns nil maps if the feed has not been modified.
+func (feed *calendarFeed) fetch(defaultLocation *time.Location) ([]icalcache.Event, map[eventKey]EventDetails, error) {
+	req, err := http.NewRequest(http.MethodGet, feed.Config.URL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if feed.Config.Username != "" {
+		req.SetBasicAuth(feed.Config.Username, feed.Config.Password)
+	}
+	if feed.lastModified != "" {
+		req.Header.Set("If-Modified-Since", feed.lastModified)
+	}
+
+	var client = feedClients[0]
+	if feed.Config.SkipTLSVerify {
+		client = feedClients[1]
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, nil, nil
+	default:
+		return nil, nil, fmt.Errorf("getting %s: %s", feed.Config.URL, resp.Status)
+	}
+
+	cal, err := ical.NewDecoder(resp.Body).Decode()
+	if errors.Is(err, io.EOF) { // no calendars in file
+		return []icalcache.Event{}, map[eventKey]EventDetails{}, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var events = []icalcache.Event{}
+	var details = make(map[eventKey]EventDetails)
+	for _, e := range cal.Events() {
+		event, eventDetails, err := decodeEvent(e, defaultLocation)
+		if err != nil {
+			return nil, nil, err
+		}
+		events = append(events, event)
+		details[keyOf(event)] = eventDetails
+	}
+	feed.lastModified = resp.Header.Get("Last-Modified")
+	return events, details, nil
+}
+
+// decodeEvent reads the properties of e like icalcache does, plus LOCATION and DESCRIPTION.
+func decodeEvent(e ical.Event, defaultLocation *time.Location) (icalcache.Event, EventDetails, error) {
+	var event icalcache.Event
+	var details EventDetails
+	var err error
+	if event.UID, err = e.Props.Text(ical.PropUID); err != nil {
+		return event, details, fmt.Errorf("getting uid: %w", err)
+	}
+	if event.Summary, err = e.Props.Text(ical.PropSummary); err != nil {
+		return event, details, fmt.Errorf("getting summary: %w", err)
+	}
+	if details.Location, err = e.Props.Text(ical.PropLocation); err != nil {
+		return event, details, fmt.Errorf("getting location: %w", err)
+	}
+	if details.Description, err = e.Props.Text(ical.PropDescription); err != nil {
+		return event, details, fmt.Errorf("getting description: %w", err)
+	}
+	u, err := e.Props.URI(ical.PropURL)
+	if err != nil {
+		return event, details, fmt.Errorf("getting url: %w", err)
+	}
+	if u != nil {
+		event.URL = u.String()
+	}
+
+	// use defaultLocation for TZIDs which can't be loaded (see https://github.com/emersion/go-ical/issues/10)
+	for _, propID := range []string{ical.PropDateTimeStart, ical.PropDateTimeEnd} {
+		if prop := e.Props.Get(propID); prop != nil {
+			if tzid := prop.Params.Get(ical.PropTimezoneID); tzid != "" {
+				if _, err := time.LoadLocation(tzid); err != nil {
+					prop.Params.Set(ical.PropTimezoneID, defaultLocation.String())
+				}
+			}
+		}
+	}
+	if event.Start, err = e.DateTimeStart(defaultLocation); err != nil {
+		return event, details, fmt.Errorf("getting start time: %w", err)
+	}
+	if event.End, err = e.DateTimeEnd(defaultLocation); err != nil {
+		return event, details, fmt.Errorf("getting end time: %w", err)
+	}
+	return event, details, nil
+}
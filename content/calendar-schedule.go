@@ -0,0 +1,97 @@
+package content
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/wansing/seal/content/calendar"
+)
+
+// A ScheduleView is passed to the template of the week and day views of CalendarBS5.
+type ScheduleView struct {
+	calendar.Schedule
+	CalendarView
+	View string // "week" or "day"
+}
+
+// Link returns the URL of the page with the same view of the day of date.
+func (view ScheduleView) Link(date time.Time) string {
+	return view.link(view.View, date)
+}
+
+// ViewLink returns the URL of the page with the given view of the first day of the schedule.
+func (view ScheduleView) ViewLink(name string) string {
+	return view.link(name, view.Begin())
+}
+
+// Schedule returns the week or day view for the query parameter "date". The language lang of the page is used unless data.Lang is set.
+func (data calendarData) Schedule(requestURL *url.URL, lang string) ScheduleView {
+	date, _ := time.ParseInLocation(time.DateOnly, requestURL.Query().Get("date"), data.Location) // zero if invalid
+	events, err := data.Feed.Get(data.Location)
+	var view = ScheduleView{
+		CalendarView: data.view(requestURL, lang, err), // don't return err, don't interrupt template execution
+		View:         data.ViewName(requestURL),
+	}
+	if view.View == "day" {
+		view.Schedule = calendar.MakeDayIn(events, date, data.Location)
+	} else {
+		view.Schedule = calendar.MakeWeekIn(events, date, data.FirstWeekday, data.Location)
+	}
+	return view
+}
+
+const calendarBS5ScheduleTemplate = `<div>
+	{{with .Error}}
+		<div class="alert alert-danger text-center">{{$.T "Error getting calendar events"}}: {{.}}</div>
+	{{end}}
+	<div class="p-2 d-flex justify-content-center align-items-center">
+		<a class="btn btn-success" href="{{$.Link .Prev}}">&#9668;</a>
+		<strong class="h3 mx-3 my-0">{{$.MonthName .Begin.Month}} {{.Begin.Year}}</strong>
+		<a class="btn btn-success" href="{{$.Link .Next}}">&#9658;</a>
+		<div class="btn-group btn-group-sm ms-3">
+			<a class="btn btn-outline-success" href="{{$.ViewLink "month"}}">{{$.T "Month"}}</a>
+			<a class="btn btn-outline-success{{if eq $.View "week"}} active{{end}}" href="{{$.ViewLink "week"}}">{{$.T "Week"}}</a>
+			<a class="btn btn-outline-success{{if eq $.View "day"}} active{{end}}" href="{{$.ViewLink "day"}}">{{$.T "Day"}}</a>
+		</div>
+	</div>
+	<div style="display: grid; grid-template-columns: 4em repeat({{len .Days}}, 1fr);">
+		<div></div>
+		{{range .Days}}
+			<div class="p-2 text-center"><strong>{{$.DayName .Begin}}</strong></div>
+		{{end}}
+		{{range .AllDay}}
+			<div class="p-1 bg-success bg-opacity-25 small" style="grid-column-start: calc({{$.NumBegin .}} + 2); grid-column-end: calc({{$.NumEnd .}} + 3);">
+				{{with .URL}}
+					<a href="{{.}}">
+				{{end}}
+				{{.Summary}}
+				{{if .URL}}
+					</a>
+				{{end}}
+			</div>
+		{{end}}
+	</div>
+	<div class="border-top border-bottom border-dark" style="display: grid; grid-template-columns: 4em repeat({{len .Days}}, 1fr);">
+		<div>
+			{{range .Hours}}
+				<div class="pe-2 text-end text-muted small" style="height: 3em;">{{$.Hour .}}</div>
+			{{end}}
+		</div>
+		{{range .Days}}
+			<div class="position-relative border-start" style="height: 72em;">
+				{{range .Events}}
+					<div class="position-absolute overflow-hidden p-1 bg-success bg-opacity-25 border border-white small" style="top: calc({{.Top}} * 3em); height: calc({{.Height}} * 3em); left: calc({{.Column}} * 100% / {{.Columns}}); width: calc(100% / {{.Columns}});">
+						<span class="text-muted">{{if .ContinuedBefore}}&hellip;{{else}}{{$.Time .Start}}{{end}}&ndash;{{if .ContinuedAfter}}&hellip;{{else}}{{$.Time .End}}{{end}}</span>
+						{{with .URL}}
+							<a href="{{.}}">
+						{{end}}
+						{{.Summary}}
+						{{if .URL}}
+							</a>
+						{{end}}
+					</div>
+				{{end}}
+			</div>
+		{{end}}
+	</div>
+</div>`
@@ -0,0 +1,114 @@
+package content
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wansing/go-ical-cache"
+	"github.com/wansing/seal/content/calendar"
+)
+
+func TestMakeSchedule(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, time.March, day, hour, minute, 0, 0, loc)
+	}
+	events := []icalcache.Event{
+		{Start: at(4, 9, 0), End: at(4, 11, 0), Summary: "a"},
+		{Start: at(4, 10, 0), End: at(4, 12, 0), Summary: "b"},
+		{Start: at(4, 10, 30), End: at(4, 11, 30), Summary: "c"},
+		{Start: at(4, 11, 0), End: at(4, 13, 0), Summary: "d"}, // reuses the column of a
+		{Start: at(4, 14, 0), End: at(4, 15, 0), Summary: "e"}, // new group
+		{Start: at(5, 22, 0), End: at(6, 1, 30), Summary: "night"},
+		{Start: at(6, 0, 0), End: at(8, 0, 0), Summary: "holiday"},
+		{Start: at(2, 0, 0), End: at(3, 0, 0), Summary: "past"},
+		{Start: at(9, 23, 0), End: at(10, 1, 0), Summary: "sunday night"},
+	}
+
+	week := calendar.MakeWeekIn(events, at(5, 12, 0), time.Monday, loc)
+	if !week.Begin().Equal(at(3, 0, 0)) || !week.Next().Equal(at(10, 0, 0)) || !week.Prev().Equal(at(-4, 0, 0)) {
+		t.Fatalf("got begin %v, next %v, prev %v", week.Begin(), week.Next(), week.Prev())
+	}
+
+	var got []string
+	for _, event := range week.Days[1].Events {
+		got = append(got, fmt.Sprintf("%s:%d/%d", event.Summary, event.Column, event.Columns))
+	}
+	if want := "a:0/3 b:1/3 c:2/3 d:0/3 e:0/1"; strings.Join(got, " ") != want {
+		t.Fatalf("got %v, want %s", got, want)
+	}
+	if e := week.Days[1].Events[4]; e.Top() != 14 || e.Height() != 1 {
+		t.Fatalf("got top %v, height %v", e.Top(), e.Height())
+	}
+
+	// crossing midnight
+	first, second := week.Days[2].Events[0], week.Days[3].Events[0]
+	if first.Summary != "night" || first.ContinuedBefore() || !first.ContinuedAfter() || first.Top() != 22 || first.Height() != 2 {
+		t.Fatalf("got %+v", first)
+	}
+	if second.Summary != "night" || !second.ContinuedBefore() || second.ContinuedAfter() || second.Top() != 0 || second.Height() != 1.5 {
+		t.Fatalf("got %+v", second)
+	}
+	if sunday := week.Days[6].Events[0]; sunday.Summary != "sunday night" || !sunday.ContinuedAfter() {
+		t.Fatalf("got %+v", sunday)
+	}
+
+	// all-day events
+	if len(week.AllDay) != 1 || week.AllDay[0].Summary != "holiday" || week.NumBegin(week.AllDay[0]) != 3 || week.NumEnd(week.AllDay[0]) != 4 {
+		t.Fatalf("got %+v", week.AllDay)
+	}
+
+	// week beginning on sunday
+	if week := calendar.MakeWeekIn(events, at(5, 12, 0), time.Sunday, loc); !week.Begin().Equal(at(2, 0, 0)) || week.Days[0].NumInWeek() != 0 {
+		t.Fatalf("got begin %v", week.Begin())
+	}
+
+	// day
+	day := calendar.MakeDayIn(events, at(6, 12, 0), loc)
+	if len(day.Days) != 1 || len(day.Days[0].Events) != 1 || len(day.AllDay) != 1 || !day.Next().Equal(at(7, 0, 0)) || day.NumEnd(day.AllDay[0]) != 0 {
+		t.Fatalf("got %+v", day)
+	}
+}
+
+func TestCalendarBS5Schedule(t *testing.T) {
+	tests := []struct {
+		filecontent string
+		cal         CalendarBS5
+		query       string
+		lang        string // of the page
+		raw         bool   // compare the markup instead of the text
+		want        []string
+	}{
+		{"view: week", CalendarBS5{}, "date=2025-03-05", "en", false, []string{"March 2025", "Monday, March 3", "Sunday, March 9", "23:00"}},
+		{"", CalendarBS5{View: "week"}, "date=2025-03-05", "de", false, []string{"März 2025", "Montag, 3. März"}},
+		{"", CalendarBS5{}, "view=day&date=2025-03-05", "en", false, []string{"Wednesday, March 5"}},
+		{"view: day", CalendarBS5{}, "view=month&year=2025&month=3", "en", false, []string{"Mon Tue Wed Thu Fri Sat Sun"}},
+		{"view: day", CalendarBS5{}, "date=2025-03-05", "", true, []string{`href="/?date=2025-03-04&amp;view=day#cal"`, `href="/?month=3&amp;view=month&amp;year=2025#cal"`}},
+		{"", CalendarBS5{}, "year=2025&month=3", "", true, []string{`href="/?date=2025-03-05&amp;view=day#cal"`}},
+		{"view: week\nschedule-template: my-schedule", CalendarBS5{}, "date=2025-03-05", "", false, []string{"mine 7"}},
+	}
+	for _, test := range tests {
+		tmpl := template.New("main")
+		template.Must(tmpl.New("my-schedule").Parse(`mine {{len .Days}}`))
+		if err := test.cal.Make(tmpl, "/", "cal", []byte(test.filecontent)); err != nil {
+			t.Fatal(err)
+		}
+		got := renderCalendar(t, tmpl, test.query, test.lang, test.raw)
+		for _, want := range test.want {
+			if !strings.Contains(got, want) {
+				t.Fatalf("%q %q: expected %q in %q", test.filecontent, test.query, want, got)
+			}
+		}
+	}
+
+	// invalid view
+	if err := (CalendarBS5{}).Make(template.New("main"), "/", "cal", []byte("view: year\nhttps://example.com/cal.ics")); err == nil {
+		t.Fatal("expected error")
+	}
+}
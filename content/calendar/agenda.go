@@ -0,0 +1,97 @@
+package calendar
+
+import (
+	"slices"
+	"time"
+
+	"github.com/wansing/go-ical-cache"
+)
+
+// An Agenda lists events grouped by day.
+type Agenda struct {
+	Begin time.Time
+	End   time.Time // exclusive, zero if the agenda is limited by the number of events only
+	Days  []AgendaDay
+	Next  time.Time // begin of the next page, zero if there are no more events
+}
+
+type AgendaDay struct {
+	Day
+	Events []AgendaEvent
+}
+
+// An AgendaEvent has its Start and End in the location of the agenda.
+type AgendaEvent struct {
+	icalcache.Event
+	AllDay   bool // begins and ends at midnight
+	Multiday bool // ends after the day on which it begins
+}
+
+// LastDay returns the end of the event, or the day before if the event ends at midnight, as the end is exclusive.
+func (event AgendaEvent) LastDay() time.Time {
+	if isMidnight(event.End) {
+		return event.End.Add(-time.Nanosecond)
+	}
+	return event.End
+}
+
+// MakeAgenda returns the events which end after begin and, if end is not zero, start before end.
+// They are grouped by the day in loc on which they begin, or by the day of begin if they have begun before.
+//
+// If limit is positive, days are added as long as the number of events does not exceed limit, but at least one day.
+// Then Next is the begin of the first day which has been left out.
+func MakeAgenda(events []icalcache.Event, begin, end time.Time, limit int, loc *time.Location) Agenda {
+	begin = begin.In(loc)
+	var agenda = Agenda{
+		Begin: begin,
+		End:   end,
+	}
+
+	var filtered []icalcache.Event
+	for _, event := range events {
+		if event.End.After(begin) && (end.IsZero() || event.Start.Before(end)) {
+			filtered = append(filtered, event)
+		}
+	}
+	slices.SortStableFunc(filtered, compareEvents)
+
+	var count int
+	for _, event := range filtered {
+		first := max(event.Start.In(loc), begin)
+		dayBegin := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, loc)
+		if len(agenda.Days) == 0 || !agenda.Days[len(agenda.Days)-1].Begin.Equal(dayBegin) {
+			agenda.Days = append(agenda.Days, AgendaDay{
+				Day: Day{
+					Begin: dayBegin,
+				},
+			})
+		}
+		event.Start = event.Start.In(loc)
+		event.End = event.End.In(loc)
+		day := &agenda.Days[len(agenda.Days)-1]
+		day.Events = append(day.Events, AgendaEvent{
+			Event:    event,
+			AllDay:   isMidnight(event.Start) && isMidnight(event.End),
+			Multiday: event.End.After(time.Date(event.Start.Year(), event.Start.Month(), event.Start.Day()+1, 0, 0, 0, 0, loc)),
+		})
+		count++
+	}
+
+	if limit > 0 && count > limit {
+		count = 0
+		for i, day := range agenda.Days {
+			count += len(day.Events)
+			if count > limit && i > 0 {
+				agenda.Next = day.Begin
+				agenda.Days = agenda.Days[:i]
+				break
+			}
+		}
+	}
+
+	return agenda
+}
+
+func isMidnight(t time.Time) bool {
+	return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0
+}
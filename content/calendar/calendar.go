@@ -10,44 +10,65 @@ type Month struct {
 	Year  int
 	Month time.Month
 	Weeks []Week
+
+	first time.Weekday
 }
 
 func (month Month) Next() Month {
-	t := time.Date(month.Year, month.Month, 1, 0, 0, 0, 0, time.Local).AddDate(0, 1, 0)
+	t := time.Date(month.Year, month.Month, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
 	return Month{
 		Year:  t.Year(),
 		Month: t.Month(),
+		first: month.first,
 	}
 }
 
 func (month Month) Prev() Month {
-	t := time.Date(month.Year, month.Month, 1, 0, 0, 0, 0, time.Local).AddDate(0, -1, 0)
+	t := time.Date(month.Year, month.Month, 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
 	return Month{
 		Year:  t.Year(),
 		Month: t.Month(),
+		first: month.first,
+	}
+}
+
+// Weekdays returns the days of the week, beginning with the first weekday of the month view.
+func (month Month) Weekdays() []time.Weekday {
+	var weekdays []time.Weekday
+	for i := range 7 {
+		weekdays = append(weekdays, (month.first+time.Weekday(i))%7)
 	}
+	return weekdays
 }
 
+// MakeMonth returns the month with weeks from Monday to Sunday in the local time zone.
+// If year or month is invalid, the current one is used.
 func MakeMonth(events []icalcache.Event, year, month int) Month {
+	return MakeMonthIn(events, year, month, time.Monday, time.Local)
+}
+
+// MakeMonthIn is like MakeMonth, but weeks begin on the given weekday, and days in the given location.
+func MakeMonthIn(events []icalcache.Event, year, month int, first time.Weekday, loc *time.Location) Month {
 	// check arguments
+	now := time.Now().In(loc)
 	if year <= 0 {
-		year = time.Now().Year()
+		year = now.Year()
 	}
 	if month < 1 || month > 12 {
-		month = int(time.Now().Month())
+		month = int(now.Month())
 	}
 
 	// get begin and end of month
-	begin := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
+	begin := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, loc)
 	end := begin.AddDate(0, 1, 0)
 
-	// go back to monday of first week
-	for begin.Weekday() != time.Monday {
+	// go back to the first weekday of the first week
+	for begin.Weekday() != first {
 		begin = begin.AddDate(0, 0, -1)
 	}
 
-	// go forth to monday of next week
-	for end.Weekday() != time.Monday {
+	// go forth to the first weekday of the next week
+	for end.Weekday() != first {
 		end = end.AddDate(0, 0, 1)
 	}
 
@@ -57,7 +78,7 @@ func MakeMonth(events []icalcache.Event, year, month int) Month {
 	// make weeks
 	var weeks []Week
 	for ; begin.Before(end); begin = begin.AddDate(0, 0, 7) {
-		_, weekNumber := begin.ISOWeek()
+		_, weekNumber := begin.AddDate(0, 0, numInWeek(time.Monday, first)).ISOWeek() // of the monday
 		var week = Week{
 			Number: weekNumber,
 			Events: filterEvents(events, begin, begin.AddDate(0, 0, 7)),
@@ -65,6 +86,7 @@ func MakeMonth(events []icalcache.Event, year, month int) Month {
 		for i := 0; i < 7; i++ {
 			week.Days[i] = Day{
 				Begin: begin.AddDate(0, 0, i),
+				first: first,
 			}
 		}
 		weeks = append(weeks, week)
@@ -74,6 +96,7 @@ func MakeMonth(events []icalcache.Event, year, month int) Month {
 		Year:  year,
 		Month: time.Month(month),
 		Weeks: weeks,
+		first: first,
 	}
 }
 
@@ -83,29 +106,33 @@ type Week struct {
 	Events []icalcache.Event
 }
 
+// Begin returns the begin of the event within the week, in the location of the week.
 func (week Week) Begin(event icalcache.Event) time.Time {
-	return max(week.Days[0].Begin, event.Start)
+	return max(week.Days[0].Begin, event.Start.In(week.Days[0].Begin.Location()))
 }
 
+// End returns the end of the event within the week, in the location of the week.
 func (week Week) End(event icalcache.Event) time.Time {
-	return min(week.Days[6].End(), event.End)
+	return min(week.Days[6].End(), event.End.In(week.Days[0].Begin.Location()))
 }
 
 func (week Week) NumInWeekBegin(event icalcache.Event) int {
-	return numInWeek(week.Begin(event))
+	return numInWeek(week.Begin(event).Weekday(), week.Days[0].first)
 }
 
 func (week Week) NumInWeekEnd(event icalcache.Event) int {
 	end := week.End(event)
 	// end time is exclusive, subtract a second if it's midnight
-	if end.Hour() == 0 && end.Minute() == 0 && end.Second() == 0 && end.Nanosecond() == 0 {
+	if isMidnight(end) {
 		end = end.Add(-1 * time.Second)
 	}
-	return numInWeek(end)
+	return numInWeek(end.Weekday(), week.Days[0].first)
 }
 
 type Day struct {
 	Begin time.Time
+
+	first time.Weekday
 }
 
 // exclusive
@@ -118,7 +145,7 @@ func (day Day) Number() int {
 }
 
 func (day Day) NumInWeek() int {
-	return numInWeek(day.Begin)
+	return numInWeek(day.Begin.Weekday(), day.first)
 }
 
 func filterEvents(events []icalcache.Event, begin, end time.Time) []icalcache.Event {
@@ -158,23 +185,7 @@ func min(a, b time.Time) time.Time {
 	}
 }
 
-// returns 0..6, but starting with monday
-func numInWeek(t time.Time) int {
-	switch t.Weekday() {
-	case time.Monday:
-		return 0
-	case time.Tuesday:
-		return 1
-	case time.Wednesday:
-		return 2
-	case time.Thursday:
-		return 3
-	case time.Friday:
-		return 4
-	case time.Saturday:
-		return 5
-	case time.Sunday:
-		return 6
-	}
-	return 0
+// numInWeek returns 0..6, starting with the first weekday
+func numInWeek(day, first time.Weekday) int {
+	return (int(day) - int(first) + 7) % 7
 }
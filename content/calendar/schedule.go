@@ -0,0 +1,221 @@
+package calendar
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/wansing/go-ical-cache"
+)
+
+// A Schedule lays out the events of one or more consecutive days on an hourly grid.
+type Schedule struct {
+	Days   []ScheduleDay
+	AllDay []icalcache.Event // events which begin and end at midnight, in the location of the schedule
+}
+
+type ScheduleDay struct {
+	Day
+	Events []TimedEvent // sorted by begin
+}
+
+// A TimedEvent is the part of an event within a day. An event which crosses midnight is split into one part per day.
+type TimedEvent struct {
+	icalcache.Event           // Start and End in the location of the schedule
+	Begin           time.Time // Start, or the begin of the day if the event has begun before
+	End             time.Time // End, or the end of the day if the event continues
+	Column          int       // 0-based column among overlapping events
+	Columns         int       // number of columns of the overlapping events
+}
+
+// Top returns the begin as hours since midnight, like 9.5 for 09:30.
+func (event TimedEvent) Top() float64 {
+	return hours(event.Begin)
+}
+
+// Height returns the duration in hours, according to the clock on the wall.
+func (event TimedEvent) Height() float64 {
+	end := hours(event.End)
+	if isMidnight(event.End) && event.End.After(event.Begin) {
+		end = 24
+	}
+	return end - event.Top()
+}
+
+// ContinuedBefore returns whether the event has begun on a previous day.
+func (event TimedEvent) ContinuedBefore() bool {
+	return event.Begin.After(event.Start)
+}
+
+// ContinuedAfter returns whether the event continues on the next day.
+func (event TimedEvent) ContinuedAfter() bool {
+	return event.End.Before(event.Event.End)
+}
+
+// Begin returns the begin of the first day.
+func (schedule Schedule) Begin() time.Time {
+	return schedule.Days[0].Begin
+}
+
+// End returns the end of the last day, exclusive.
+func (schedule Schedule) End() time.Time {
+	return schedule.Days[len(schedule.Days)-1].End()
+}
+
+// Next returns the begin of the following schedule of the same length.
+func (schedule Schedule) Next() time.Time {
+	return schedule.Begin().AddDate(0, 0, len(schedule.Days))
+}
+
+// Prev returns the begin of the preceding schedule of the same length.
+func (schedule Schedule) Prev() time.Time {
+	return schedule.Begin().AddDate(0, 0, -len(schedule.Days))
+}
+
+// Hours returns 0..23, for the rows of the grid.
+func (schedule Schedule) Hours() []int {
+	var hours = make([]int, 24)
+	for i := range hours {
+		hours[i] = i
+	}
+	return hours
+}
+
+// NumBegin returns the index of the day on which the all-day event begins within the schedule.
+func (schedule Schedule) NumBegin(event icalcache.Event) int {
+	return schedule.dayIndex(max(schedule.Begin(), event.Start))
+}
+
+// NumEnd returns the index of the last day of the all-day event within the schedule.
+func (schedule Schedule) NumEnd(event icalcache.Event) int {
+	// end time is exclusive, subtract a second
+	return schedule.dayIndex(min(schedule.End(), event.End).Add(-1 * time.Second))
+}
+
+func (schedule Schedule) dayIndex(t time.Time) int {
+	for i, day := range schedule.Days {
+		if t.Before(day.End()) {
+			return i
+		}
+	}
+	return len(schedule.Days) - 1
+}
+
+// MakeWeek returns the week from Monday to Sunday in the local time zone which contains date.
+// If date is zero, the current week is used.
+func MakeWeek(events []icalcache.Event, date time.Time) Schedule {
+	return MakeWeekIn(events, date, time.Monday, time.Local)
+}
+
+// MakeWeekIn is like MakeWeek, but the week begins on the given weekday, and days in the given location.
+func MakeWeekIn(events []icalcache.Event, date time.Time, first time.Weekday, loc *time.Location) Schedule {
+	begin := midnight(date, loc)
+	// go back to the first weekday
+	for begin.Weekday() != first {
+		begin = begin.AddDate(0, 0, -1)
+	}
+	return makeSchedule(events, begin, 7, first, loc)
+}
+
+// MakeDay returns the day in the local time zone which contains date. If date is zero, the current day is used.
+func MakeDay(events []icalcache.Event, date time.Time) Schedule {
+	return MakeDayIn(events, date, time.Local)
+}
+
+// MakeDayIn is like MakeDay, but in the given location.
+func MakeDayIn(events []icalcache.Event, date time.Time, loc *time.Location) Schedule {
+	return makeSchedule(events, midnight(date, loc), 1, time.Monday, loc)
+}
+
+// midnight returns the begin of the day of date in loc, or of the current day if date is zero.
+func midnight(date time.Time, loc *time.Location) time.Time {
+	if date.IsZero() {
+		date = time.Now()
+	}
+	date = date.In(loc)
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+}
+
+func makeSchedule(events []icalcache.Event, begin time.Time, days int, first time.Weekday, loc *time.Location) Schedule {
+	var schedule Schedule
+	for i := range days {
+		schedule.Days = append(schedule.Days, ScheduleDay{
+			Day: Day{
+				Begin: begin.AddDate(0, 0, i),
+				first: first,
+			},
+		})
+	}
+
+	for _, event := range filterEvents(events, schedule.Begin(), schedule.End()) {
+		event.Start = event.Start.In(loc)
+		event.End = event.End.In(loc)
+		if isMidnight(event.Start) && isMidnight(event.End) && event.End.After(event.Start) {
+			schedule.AllDay = append(schedule.AllDay, event)
+			continue
+		}
+		for i := range schedule.Days {
+			day := &schedule.Days[i]
+			if overlaps(event.Start, event.End, day.Begin, day.End()) {
+				day.Events = append(day.Events, TimedEvent{
+					Event: event,
+					Begin: max(day.Begin, event.Start),
+					End:   min(day.End(), event.End),
+				})
+			}
+		}
+	}
+
+	slices.SortStableFunc(schedule.AllDay, compareEvents)
+	for i := range schedule.Days {
+		layout(schedule.Days[i].Events)
+	}
+	return schedule
+}
+
+// layout sorts the events and assigns columns, so that overlapping events are placed side by side.
+// Each group of transitively overlapping events gets the same number of columns.
+func layout(events []TimedEvent) {
+	slices.SortStableFunc(events, func(a, b TimedEvent) int {
+		return cmp.Or(a.Begin.Compare(b.Begin), b.End.Compare(a.End), compareEvents(a.Event, b.Event))
+	})
+
+	var group []int            // indices of the current group
+	var columnEnds []time.Time // end of the last event in each column of the current group
+	var groupEnd time.Time
+	finish := func() {
+		for _, i := range group {
+			events[i].Columns = len(columnEnds)
+		}
+		group, columnEnds = nil, nil
+	}
+
+	for i := range events {
+		if len(group) > 0 && !events[i].Begin.Before(groupEnd) {
+			finish()
+		}
+		column := slices.IndexFunc(columnEnds, func(end time.Time) bool {
+			return !end.After(events[i].Begin)
+		})
+		if column < 0 {
+			column = len(columnEnds)
+			columnEnds = append(columnEnds, time.Time{})
+		}
+		columnEnds[column] = events[i].End
+		events[i].Column = column
+		if len(group) == 0 || events[i].End.After(groupEnd) {
+			groupEnd = events[i].End
+		}
+		group = append(group, i)
+	}
+	finish()
+}
+
+func compareEvents(a, b icalcache.Event) int {
+	return cmp.Or(a.Start.Compare(b.Start), a.End.Compare(b.End), cmp.Compare(a.Summary, b.Summary))
+}
+
+// hours returns the time of day of t in hours.
+func hours(t time.Time) float64 {
+	return float64(t.Hour()) + float64(t.Minute())/60 + float64(t.Second())/3600
+}
@@ -46,6 +46,30 @@ func AbsHrefSrc(htm, urlpath string) string {
 	return result.String()
 }
 
+// dynamicMarker is the name of an empty template. Its presence marks the associated templates as dynamic.
+const dynamicMarker = "$dynamic"
+
+// MarkDynamic marks the templates associated with t as dynamic, i.e. their output depends on the request (like the query string) or on the current time.
+// Such output must not be exported or cached.
+func MarkDynamic(t *template.Template) {
+	t.New(dynamicMarker).Parse(`{{/* output depends on the request or on the current time */}}`)
+}
+
+// IsDynamic returns whether MarkDynamic has been called on any template associated with t, including templates which t has been cloned from.
+// Use MarksDynamic to find out about a single ContentFunc.
+func IsDynamic(t *template.Template) bool {
+	return t.Lookup(dynamicMarker) != nil
+}
+
+// MarksDynamic calls fn and returns whether fn has called MarkDynamic on a template associated with t.
+// Unlike IsDynamic, it is not affected by templates which have been marked before.
+func MarksDynamic(t *template.Template, fn func() error) (bool, error) {
+	before := t.Lookup(dynamicMarker)
+	err := fn()
+	after := t.Lookup(dynamicMarker) // MarkDynamic replaces the marker
+	return after != nil && after != before, err
+}
+
 func ParseWithData(t *template.Template, text string, dataFunc any) error {
 	randomName := "F" + rand.Text() // always start with a letter
 	t.Funcs(template.FuncMap{
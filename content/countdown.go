@@ -37,14 +37,15 @@ func Countdown(t *template.Template, urlpath, fileroot string, filecontent []byt
 	tmplHtml = strings.TrimSpace(tmplHtml)
 	if tmplHtml == "" {
 		tmplHtml = `
-			<span id="years">  {{$years  }}</span> years,
-			<span id="months"> {{$months }}</span> months,
-			<span id="days">   {{$days   }}</span> days,
-			<span id="hours">  {{$hours  }}</span> hours,
-			<span id="minutes">{{$minutes}}</span> minutes,
-			<span id="seconds">{{$seconds}}</span> seconds`
+			<span id="years">  {{$years  }}</span> {{$.T "years"}},
+			<span id="months"> {{$months }}</span> {{$.T "months"}},
+			<span id="days">   {{$days   }}</span> {{$.T "days"}},
+			<span id="hours">  {{$hours  }}</span> {{$.T "hours"}},
+			<span id="minutes">{{$minutes}}</span> {{$.T "minutes"}},
+			<span id="seconds">{{$seconds}}</span> {{$.T "seconds"}}`
 	}
 
+	MarkDynamic(t)
 	return ParseWithData(
 		t,
 		`<script type="text/javascript">
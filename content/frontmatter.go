@@ -0,0 +1,198 @@
+package content
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Meta is the front matter of a content file. Values are of type string, bool or []string.
+type Meta map[string]any
+
+// String returns the value of key if it is a string.
+func (meta Meta) String(key string) string {
+	s, _ := meta[key].(string)
+	return s
+}
+
+// Int returns the value of key as an integer, and whether it is a valid integer.
+func (meta Meta) Int(key string) (int, bool) {
+	i, err := strconv.Atoi(meta.String(key))
+	return i, err == nil
+}
+
+// Bool returns the value of key if it is a bool.
+func (meta Meta) Bool(key string) bool {
+	b, _ := meta[key].(bool)
+	return b
+}
+
+// Strings returns the value of key if it is a list. A single string is returned as a list with one element.
+func (meta Meta) Strings(key string) []string {
+	switch v := meta[key].(type) {
+	case []string:
+		return v
+	case string:
+		return []string{v}
+	default:
+		return nil
+	}
+}
+
+func (meta Meta) Title() string {
+	return meta.String("title")
+}
+
+func (meta Meta) Description() string {
+	return meta.String("description")
+}
+
+func (meta Meta) Draft() bool {
+	return meta.Bool("draft")
+}
+
+func (meta Meta) Tags() []string {
+	return meta.Strings("tags")
+}
+
+func (meta Meta) Layout() string {
+	return meta.String("layout")
+}
+
+// Date parses the "date" value. It returns the zero time if the value is missing or invalid.
+func (meta Meta) Date() time.Time {
+	date, _ := ParseDate(meta.String("date"))
+	return date
+}
+
+// Updated parses the "updated" value, falling back to Date.
+func (meta Meta) Updated() time.Time {
+	if updated, err := ParseDate(meta.String("updated")); err == nil {
+		return updated
+	}
+	return meta.Date()
+}
+
+// ParseDate accepts "2006-01-02", "2006-01-02 15:04" and RFC 3339.
+// Dates without a time zone are in time.Local.
+func ParseDate(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02", "2006-01-02 15:04", "2006-01-02 15:04:05"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date: %q", s)
+}
+
+// SplitFrontMatter splits an optional front matter block off the filecontent and returns its values and the remaining body.
+//
+// The block must start in the first line. It is enclosed either by "---" lines and contains "key: value" pairs (YAML-style),
+// or by "+++" lines and contains "key = value" pairs (TOML-style).
+// Values can be strings (optionally quoted), true, false, or lists like [a, "b"].
+// In YAML-style blocks, lists can also be given as "- item" lines below a key without value.
+// Empty lines and lines starting with # are ignored.
+func SplitFrontMatter(filecontent []byte) (Meta, []byte, error) {
+	var delimiter, separator string
+	switch {
+	case hasDelimiterLine(filecontent, "---"):
+		delimiter, separator = "---", ":"
+	case hasDelimiterLine(filecontent, "+++"):
+		delimiter, separator = "+++", "="
+	default:
+		return nil, filecontent, nil
+	}
+
+	lines := strings.SplitAfter(string(filecontent), "\n") // lines[0] is the opening delimiter
+	var end = -1
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == delimiter {
+			end = i
+			break
+		}
+	}
+	if end == -1 {
+		return nil, filecontent, nil // no closing delimiter, so it's not front matter
+	}
+
+	meta, err := parseMeta(lines[1:end], separator, delimiter == "---", 2)
+	if err != nil {
+		return nil, filecontent, fmt.Errorf("front matter %w", err)
+	}
+	return meta, []byte(strings.Join(lines[end+1:], "")), nil
+}
+
+// ParseMeta parses a whole file of YAML-style "key: value" pairs, like the content of a front matter block.
+func ParseMeta(data []byte) (Meta, error) {
+	return parseMeta(strings.Split(string(data), "\n"), ":", true, 1)
+}
+
+// parseMeta parses lines of "key<separator>value" pairs. The firstLine number is used in error messages.
+func parseMeta(lines []string, separator string, yamlLists bool, firstLine int) (Meta, error) {
+	var meta = make(Meta)
+	var listKey string // YAML-style list
+	for i := range lines {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if item, ok := strings.CutPrefix(trimmed, "- "); ok && listKey != "" && yamlLists {
+			list, _ := meta[listKey].([]string)
+			meta[listKey] = append(list, unquote(strings.TrimSpace(item)))
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, separator)
+		if !ok {
+			return nil, fmt.Errorf("line %d: missing %q", firstLine+i, separator)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if key == "" {
+			return nil, fmt.Errorf("line %d: missing key", firstLine+i)
+		}
+
+		listKey = ""
+		switch {
+		case value == "":
+			listKey = key
+			meta[key] = []string{}
+		case value == "true":
+			meta[key] = true
+		case value == "false":
+			meta[key] = false
+		case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+			var list = []string{}
+			for _, item := range strings.Split(value[1:len(value)-1], ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					list = append(list, unquote(item))
+				}
+			}
+			meta[key] = list
+		default:
+			meta[key] = unquote(value)
+		}
+	}
+	return meta, nil
+}
+
+func hasDelimiterLine(filecontent []byte, delimiter string) bool {
+	firstLine, _, found := bytes.Cut(filecontent, []byte("\n"))
+	return found && strings.TrimSpace(string(firstLine)) == delimiter
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		if s[0] == '"' {
+			if unquoted, err := strconv.Unquote(s); err == nil {
+				return unquoted
+			}
+		}
+		return s[1 : len(s)-1]
+	}
+	return s
+}
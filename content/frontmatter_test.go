@@ -0,0 +1,82 @@
+package content
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSplitFrontMatter(t *testing.T) {
+	tests := []struct {
+		input    string
+		wantMeta Meta
+		wantBody string
+	}{
+		{"# No front matter", nil, "# No front matter"},
+		{"---\nno closing delimiter", nil, "---\nno closing delimiter"},
+		{
+			"---\ntitle: \"Hello: World\"\ndraft: true\ntags: [a, 'b c']\n# comment\n---\n# Body\n",
+			Meta{"title": "Hello: World", "draft": true, "tags": []string{"a", "b c"}},
+			"# Body\n",
+		},
+		{
+			"---\ntags:\n  - a\n  - b\n---\nBody",
+			Meta{"tags": []string{"a", "b"}},
+			"Body",
+		},
+		{
+			"+++\ntitle = \"TOML\"\ndate = 2025-01-02\n+++\nBody",
+			Meta{"title": "TOML", "date": "2025-01-02"},
+			"Body",
+		},
+	}
+
+	for _, test := range tests {
+		meta, body, err := SplitFrontMatter([]byte(test.input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(body) != test.wantBody {
+			t.Fatalf("%q: got body %q, want %q", test.input, body, test.wantBody)
+		}
+		if len(meta) != len(test.wantMeta) {
+			t.Fatalf("%q: got meta %v, want %v", test.input, meta, test.wantMeta)
+		}
+		for key, want := range test.wantMeta {
+			if list, ok := want.([]string); ok {
+				if !slices.Equal(meta.Strings(key), list) {
+					t.Fatalf("%q: got %s %v, want %v", test.input, key, meta[key], want)
+				}
+			} else if meta[key] != want {
+				t.Fatalf("%q: got %s %v, want %v", test.input, key, meta[key], want)
+			}
+		}
+	}
+
+	if _, _, err := SplitFrontMatter([]byte("---\ninvalid\n---\n")); err == nil {
+		t.Fatal("expected error for line without separator")
+	}
+}
+
+func TestMetaDate(t *testing.T) {
+	meta := Meta{"date": "2025-01-02"}
+	if got, want := meta.Date(), time.Date(2025, 1, 2, 0, 0, 0, 0, time.Local); !got.Equal(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if !(Meta{"date": "yesterday"}).Date().IsZero() {
+		t.Fatal("expected zero time for invalid date")
+	}
+}
+
+func TestParseMeta(t *testing.T) {
+	meta, err := ParseMeta([]byte("title: About\norder: 2\nhidden: true\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order, ok := meta.Int("order"); meta.Title() != "About" || !meta.Bool("hidden") || !ok || order != 2 {
+		t.Fatalf("unexpected meta: %v", meta)
+	}
+	if _, err := ParseMeta([]byte("title: About\nbroken\n")); err == nil || err.Error() != `line 2: missing ":"` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
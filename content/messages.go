@@ -0,0 +1,79 @@
+package content
+
+import "strings"
+
+// Messages is the message catalog of the built-in widgets. It maps lowercase languages like "de" or "pt-br" to English messages to translated messages.
+// Languages can be added and messages can be overridden before the content is loaded.
+var Messages = map[string]map[string]string{
+	"de": {
+		// calendar
+		"January":                       "Januar",
+		"February":                      "Februar",
+		"March":                         "März",
+		"April":                         "April",
+		"May":                           "Mai",
+		"June":                          "Juni",
+		"July":                          "Juli",
+		"August":                        "August",
+		"September":                     "September",
+		"October":                       "Oktober",
+		"November":                      "November",
+		"December":                      "Dezember",
+		"Mon":                           "Mo",
+		"Tue":                           "Di",
+		"Wed":                           "Mi",
+		"Thu":                           "Do",
+		"Fri":                           "Fr",
+		"Sat":                           "Sa",
+		"Sun":                           "So",
+		"Error getting calendar events": "Fehler beim Abrufen der Termine",
+		"Month":                         "Monat",
+		"Week":                          "Woche",
+		"Day":                           "Tag",
+		// calendar agenda
+		"Monday":                   "Montag",
+		"Tuesday":                  "Dienstag",
+		"Wednesday":                "Mittwoch",
+		"Thursday":                 "Donnerstag",
+		"Friday":                   "Freitag",
+		"Saturday":                 "Samstag",
+		"Sunday":                   "Sonntag",
+		"{weekday}, {month} {day}": "{weekday}, {day}. {month}",
+		"until":                    "bis",
+		"Earlier":                  "Früher",
+		"Later":                    "Später",
+		"Upcoming events":          "Anstehende Termine",
+		"No upcoming events.":      "Keine anstehenden Termine.",
+		// countdown
+		"years":   "Jahre",
+		"months":  "Monate",
+		"days":    "Tage",
+		"hours":   "Stunden",
+		"minutes": "Minuten",
+		"seconds": "Sekunden",
+		// miniblog
+		"Newer posts":  "Neuere Beiträge",
+		"Older posts":  "Ältere Beiträge",
+		"Back to Blog": "Zurück zum Blog",
+		// search
+		"Search":      "Suchen",
+		"No results.": "Keine Ergebnisse.",
+	},
+}
+
+// Message returns the translation of the English message into lang.
+// Messages like "15:04" are time layouts, so the time format can be translated too. A region like in "de-AT" falls back to the base language.
+// If there is no translation, msg is returned.
+func Message(lang, msg string) string {
+	lang = strings.ToLower(lang)
+	for {
+		if translated, ok := Messages[lang][msg]; ok {
+			return translated
+		}
+		i := strings.LastIndexAny(lang, "-_")
+		if i < 0 {
+			return msg
+		}
+		lang = lang[:i]
+	}
+}
@@ -0,0 +1,23 @@
+package content
+
+import "testing"
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		lang string
+		msg  string
+		want string
+	}{
+		{"de", "March", "März"},
+		{"de-AT", "March", "März"},
+		{"de_CH", "Older posts", "Ältere Beiträge"},
+		{"en", "March", "March"},
+		{"", "March", "March"},
+		{"de", "unknown", "unknown"},
+	}
+	for _, test := range tests {
+		if got := Message(test.lang, test.msg); got != test.want {
+			t.Fatalf("%s %q: expected %q, got %q", test.lang, test.msg, test.want, got)
+		}
+	}
+}
@@ -11,6 +11,7 @@ func RandomHTML(t *template.Template, urlpath, fileroot string, filecontent []by
 	for line := range strings.Lines(string(filecontent)) {
 		options = append(options, AbsHrefSrc(line, urlpath))
 	}
+	MarkDynamic(t)
 	return ParseWithData(
 		t,
 		"{{.}}",
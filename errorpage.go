@@ -0,0 +1,112 @@
+package seal
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var bufPool = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
+
+func getBuffer() *bytes.Buffer {
+	buf := bufPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// errorPageNames are the names of content files which define error pages, like "500.md".
+// They are rendered in place of the "main" template of the layout and don't make a page on their own.
+var errorPageNames = []string{"404", "500"}
+
+// ErrorData is passed to error page templates.
+type ErrorData struct {
+	TemplateData
+	Status     int
+	StatusText string
+}
+
+// errorPageTmpl returns a clone of the layout tmpl which renders the error page template name as "main",
+// or nil if there is no such template. It must be called before tmpl is executed.
+func errorPageTmpl(tmpl *template.Template, name string) *template.Template {
+	if tmpl.Lookup(name) == nil {
+		return nil
+	}
+	errTmpl, err := tmpl.Clone()
+	if err != nil {
+		return nil
+	}
+	if _, err := errTmpl.New("main").Parse(`{{template "` + name + `" .}}`); err != nil {
+		return nil
+	}
+	return errTmpl
+}
+
+// A handlerErrorPage is attached to the requests of a handler directory, see ServeError.
+type handlerErrorPage struct {
+	snap    *snapshot
+	errTmpl *template.Template // see errorPageTmpl
+	fspath  string
+	lang    string
+}
+
+type handlerErrorPageKey struct{}
+
+// withErrorPage returns a handler which attaches a handlerErrorPage to the request before passing it to h.
+func (snap *snapshot) withErrorPage(h http.Handler, errTmpl *template.Template, fspath, lang string) http.Handler {
+	var page = handlerErrorPage{
+		snap:    snap,
+		errTmpl: errTmpl,
+		fspath:  fspath,
+		lang:    lang,
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), handlerErrorPageKey{}, page)))
+	})
+}
+
+// ServeError is for http.Handlers returned by a HandlerGen. It records err in the error list of the snapshot which serves r,
+// and replies with status code 500 and the "500" error page of the handler directory. Outside of a Server, it logs err and replies with a plain text.
+func ServeError(w http.ResponseWriter, r *http.Request, err error) {
+	page, ok := r.Context().Value(handlerErrorPageKey{}).(handlerErrorPage)
+	if !ok {
+		log.Printf("error %s: %v", r.URL.Path, err)
+		http.Error(w, "500 internal server error", http.StatusInternalServerError)
+		return
+	}
+	page.snap.logRuntime(err, r.URL.Path, page.fspath)
+	page.snap.serveError(w, r, page.errTmpl, http.StatusInternalServerError, TemplateData{
+		RequestURL: r.URL,
+		URLPath:    r.URL.Path,
+		Lang:       page.lang,
+	})
+}
+
+// serveError replies with the given status code. It renders errTmpl if it is not nil, else it sends a plain text.
+func (snap *snapshot) serveError(w http.ResponseWriter, r *http.Request, errTmpl *template.Template, status int, data TemplateData) {
+	if errTmpl != nil {
+		buf := getBuffer()
+		defer bufPool.Put(buf)
+		err := errTmpl.Execute(buf, ErrorData{
+			TemplateData: data,
+			Status:       status,
+			StatusText:   http.StatusText(status),
+		})
+		if err == nil {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(status)
+			w.Write(buf.Bytes())
+			return
+		}
+		snap.logRuntime(err, r.URL.Path, "")
+	}
+	http.Error(w, strconv.Itoa(status)+" "+strings.ToLower(http.StatusText(status)), status)
+}
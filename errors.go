@@ -0,0 +1,242 @@
+package seal
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Severity string
+
+const (
+	SeverityWarning Severity = "warning" // does not make SafeReload reject a snapshot
+	SeverityError   Severity = "error"
+)
+
+// An Error is a problem found while reading the content tree or serving a request.
+type Error struct {
+	Message    string    `json:"message"`
+	URLPath    string    `json:"urlpath"`
+	FSPath     string    `json:"fspath,omitempty"` // offending file or directory
+	Line       int       `json:"line,omitempty"`   // line in the template text, if available
+	Column     int       `json:"column,omitempty"`
+	Severity   Severity  `json:"severity"`
+	Generation int       `json:"generation"` // of the snapshot
+	Time       time.Time `json:"time"`
+	Err        error     `json:"-"`
+}
+
+func (e Error) Error() string {
+	var location = e.FSPath
+	if e.Line > 0 {
+		location += ":" + strconv.Itoa(e.Line)
+		if e.Column > 0 {
+			location += ":" + strconv.Itoa(e.Column)
+		}
+	}
+	if location == "" {
+		return e.Message
+	}
+	return location + ": " + e.Message
+}
+
+func (e Error) Unwrap() error {
+	return e.Err
+}
+
+// like "template: name:12: message", "template: name:12:34: executing ..." or "html/template:name:12:34: ..."
+var templateErrorLocation = regexp.MustCompile(`template: ?([^:]*):([0-9]+)(?::([0-9]+))?:`)
+
+// templateLine extracts template name, line and column from a template parse or execution error, if available.
+func templateLine(err error) (string, int, int) {
+	if m := templateErrorLocation.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[2])
+		column, _ := strconv.Atoi(m[3])
+		return m[1], line, column
+	}
+	var htmlErr *template.Error
+	if errors.As(err, &htmlErr) && htmlErr.Line > 0 {
+		return htmlErr.Name, htmlErr.Line, 0
+	}
+	return "", 0, 0
+}
+
+// A frontMatterError is a template error whose line refers to the content without front matter.
+type frontMatterError struct {
+	err   error
+	lines map[string]int // template name => number of front matter lines, which have been stripped before parsing
+}
+
+func (e frontMatterError) Error() string {
+	return e.err.Error()
+}
+
+func (e frontMatterError) Unwrap() error {
+	return e.err
+}
+
+func (snap *snapshot) log(severity Severity, err error, urlpath, fspath string) {
+	snap.errsLock.Lock()
+	defer snap.errsLock.Unlock()
+	snap.errs = append(snap.errs, snap.newError(severity, err, urlpath, fspath))
+	log.Printf("%s %s: %v", severity, urlpath, snap.errs[len(snap.errs)-1])
+}
+
+// maxRuntimeErrors limits the number of errors which logRuntime records per snapshot.
+const maxRuntimeErrors = 100
+
+// logRuntime records an error which occurred while serving a request. Repeated errors are recorded once.
+func (snap *snapshot) logRuntime(err error, urlpath, fspath string) {
+	snap.errsLock.Lock()
+	defer snap.errsLock.Unlock()
+	if snap.runtimeErrs >= maxRuntimeErrors {
+		return
+	}
+	e := snap.newError(SeverityError, err, urlpath, fspath)
+	for _, existing := range snap.errs {
+		if existing.URLPath == e.URLPath && existing.Message == e.Message {
+			return
+		}
+	}
+	snap.errs = append(snap.errs, e)
+	snap.runtimeErrs++
+	log.Printf("%s %s: %v", e.Severity, urlpath, e)
+}
+
+func (snap *snapshot) newError(severity Severity, err error, urlpath, fspath string) Error {
+	name, line, column := templateLine(err)
+	var fm frontMatterError
+	if line > 0 && errors.As(err, &fm) {
+		line += fm.lines[name]
+	}
+	return Error{
+		Message:    err.Error(),
+		URLPath:    urlpath,
+		FSPath:     fspath,
+		Line:       line,
+		Column:     column,
+		Severity:   severity,
+		Generation: snap.generation,
+		Time:       time.Now(),
+		Err:        err,
+	}
+}
+
+// errors returns a copy of the errors of the snapshot.
+func (snap *snapshot) errors() []Error {
+	snap.errsLock.Lock()
+	defer snap.errsLock.Unlock()
+	return slices.Clone(snap.errs)
+}
+
+// hasErrors returns whether the snapshot contains errors with SeverityError.
+func (snap *snapshot) hasErrors() bool {
+	for _, e := range snap.errors() {
+		if e.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+var errorsTmpl = template.Must(template.New("").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Errors</title>
+	<style>
+		table { border-collapse: collapse; }
+		td, th { border: 1px solid #ccc; padding: 0.25em 0.5em; text-align: left; vertical-align: top; }
+		.error { color: #a00; }
+		.warning { color: #a60; }
+	</style>
+</head>
+<body>
+	<h1>Errors</h1>
+	<p>
+		Generation {{.Generation}}
+		{{if .Rejected}}has been rejected, still serving generation {{.Serving}}{{end}}
+	</p>
+	{{if .Errors}}
+		<table>
+			<tr><th>Severity</th><th>URL path</th><th>File</th><th>Message</th><th>Time</th></tr>
+			{{range .Errors}}
+				<tr class="{{.Severity}}">
+					<td>{{.Severity}}</td>
+					<td><a href="{{.URLPath}}">{{.URLPath}}</a></td>
+					<td>{{.FSPath}}{{with .Line}}:{{.}}{{end}}{{with .Column}}:{{.}}{{end}}</td>
+					<td>{{.Message}}</td>
+					<td>{{.Time.Format "2006-01-02 15:04:05"}}</td>
+				</tr>
+			{{end}}
+		</table>
+	{{else}}
+		<p>No errors.</p>
+	{{end}}
+</body>
+</html>`))
+
+// ErrorsHandler returns a handler which sends the errors of the most recent reload, including errors which occurred while serving requests.
+// If SafeReload is enabled, that reload might have been rejected. Then the errors of the snapshot which is still served are sent too.
+//
+// Query parameters: "prefix" filters by URL path prefix, "severity" filters by severity,
+// "format=html" returns an HTML page instead of JSON. HTML is also returned if the client prefers it over JSON.
+func (srv *Server) ErrorsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var generation, serving int
+		var all []Error
+		latest := srv.latest.Load()
+		if latest != nil {
+			generation = latest.generation
+			all = latest.errors()
+		}
+		if current := srv.current.Load(); current != nil {
+			serving = current.generation
+			if current != latest {
+				all = append(all, current.errors()...)
+			}
+		}
+
+		var errs = []Error{} // json "[]" instead of "null"
+		prefix := r.URL.Query().Get("prefix")
+		severity := Severity(r.URL.Query().Get("severity"))
+		for _, e := range all {
+			if !strings.HasPrefix(e.URLPath, prefix) {
+				continue
+			}
+			if severity != "" && e.Severity != severity {
+				continue
+			}
+			errs = append(errs, e)
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "html" || (format == "" && strings.Contains(r.Header.Get("Accept"), "text/html")) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			errorsTmpl.Execute(w, struct {
+				Errors     []Error
+				Generation int
+				Rejected   bool
+				Serving    int
+			}{
+				Errors:     errs,
+				Generation: generation,
+				Rejected:   generation != serving,
+				Serving:    serving,
+			})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		enc.Encode(errs)
+	}
+}
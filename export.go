@@ -0,0 +1,109 @@
+package seal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Export renders every route of the current snapshot and writes the output into the directory outDir.
+// Pages are written as index.html files, so URL paths stay the same on static hosting.
+//
+// Dynamic pages (see content.MarkDynamic) are exported as rendered now, which is probably not what the user wants.
+// Their URL paths are returned so they can be reported.
+//
+// srv.BaseURL is required, because the exported sitemap.xml and robots.txt contain absolute URLs which can't be derived from a request.
+func (srv *Server) Export(outDir string) ([]string, error) {
+	if srv.BaseURL == "" {
+		return nil, errors.New("export requires a base URL")
+	}
+	snap := srv.current.Load()
+	if snap == nil {
+		return nil, errors.New("nothing to export, call Reload first")
+	}
+
+	var dynamic []string
+	var errs []error
+	for _, r := range snap.routes {
+		if r.Dynamic {
+			dynamic = append(dynamic, r.URLPath)
+		}
+		if err := exportRoute(snap.mux, outDir, r); err != nil {
+			errs = append(errs, fmt.Errorf("exporting %s: %w", r.URLPath, err))
+		}
+	}
+	return dynamic, errors.Join(errs...)
+}
+
+func exportRoute(h http.Handler, outDir string, r route) error {
+	resp := render(h, r.URLPath)
+	if resp.code != http.StatusOK {
+		return fmt.Errorf("got status code %d", resp.code)
+	}
+
+	dst := filepath.Join(outDir, filepath.FromSlash(exportFilename(r)))
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, resp.body.Bytes(), 0644)
+}
+
+// A bufferedResponse is an http.ResponseWriter which keeps the status code and the body, for rendering routes without a client.
+type bufferedResponse struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func (resp *bufferedResponse) Header() http.Header {
+	return resp.header
+}
+
+func (resp *bufferedResponse) WriteHeader(code int) {
+	if resp.code == 0 {
+		resp.code = code
+	}
+}
+
+func (resp *bufferedResponse) Write(p []byte) (int, error) {
+	resp.WriteHeader(http.StatusOK)
+	return resp.body.Write(p)
+}
+
+// render serves a GET request for urlpath by h.
+func render(h http.Handler, urlpath string) *bufferedResponse {
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        &url.URL{Path: urlpath},
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+	}
+	resp := &bufferedResponse{
+		header: make(http.Header),
+	}
+	h.ServeHTTP(resp, req)
+	if resp.code == 0 {
+		resp.code = http.StatusOK // like net/http, if the handler wrote nothing
+	}
+	return resp
+}
+
+// exportFilename returns the slash-separated path of the file which r is written to, relative to the output directory.
+func exportFilename(r route) string {
+	name := strings.TrimPrefix(r.URLPath, "/")
+	switch {
+	case r.Static || r.Feed:
+		return name
+	case name == "" || strings.HasSuffix(name, "/"):
+		return name + "index.html"
+	default:
+		return name + "/index.html"
+	}
+}
@@ -3,6 +3,7 @@ module github.com/wansing/seal
 go 1.24.0
 
 require (
+	github.com/emersion/go-ical v0.0.0-20240127095438-fc1c9d8fb2b6
 	github.com/icza/gox v0.0.0-20230330130131-23e1aaac139e
 	github.com/mattn/go-isatty v0.0.19
 	github.com/wansing/go-ical-cache v0.0.0-20250107090723-c5928d9c5ade
@@ -11,7 +12,6 @@ require (
 )
 
 require (
-	github.com/emersion/go-ical v0.0.0-20240127095438-fc1c9d8fb2b6 // indirect
 	github.com/teambition/rrule-go v1.8.2 // indirect
 	golang.org/x/sys v0.40.0 // indirect
 )
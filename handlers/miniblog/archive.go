@@ -0,0 +1,140 @@
+package miniblog
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/wansing/seal"
+)
+
+const previewList = `<ul>
+	{{range .Previews}}
+		<li id="{{.Anchor}}">
+			<a href="{{.URL}}">{{.Date}} {{.Title}}</a>
+		</li>
+	{{end}}
+</ul>`
+
+type tagLink struct {
+	Name string
+	URL  string
+}
+
+type archiveLink struct {
+	Year  int
+	Month time.Month // zero in year links
+	Count int
+	URL   string
+}
+
+type IndexData struct {
+	seal.TemplateData
+	Previews []postPreview
+	Page     int // starting at 1
+	Pages    int
+	PrevURL  string // newer posts, empty on the first page
+	NextURL  string // older posts, empty on the last page
+	Archive  []archiveLink
+	Tags     []tagLink
+}
+
+// ArchiveData is used for year and month archives.
+type ArchiveData struct {
+	seal.TemplateData
+	BackURL  string
+	Title    string // like "2025" or "2025-01"
+	Year     int
+	Month    time.Month // zero in year archives
+	Previews []postPreview
+}
+
+type TagData struct {
+	seal.TemplateData
+	BackURL  string
+	Tag      string
+	Previews []postPreview
+}
+
+// pageURL returns the URL path of an index page. The first page is the blog root.
+func pageURL(urlpath string, page int) string {
+	if page <= 1 {
+		return urlpath + "/"
+	}
+	return urlpath + "/page/" + strconv.Itoa(page)
+}
+
+// paginate splits previews into pages. There is at least one page, even if it is empty.
+func paginate(previews []postPreview, pageSize int) [][]postPreview {
+	var pages [][]postPreview
+	for chunk := range slices.Chunk(previews, pageSize) {
+		pages = append(pages, chunk)
+	}
+	if len(pages) == 0 {
+		pages = append(pages, nil)
+	}
+	return pages
+}
+
+func yearURL(urlpath string, year int) string {
+	return path.Join(urlpath, strconv.Itoa(year))
+}
+
+func monthURL(urlpath string, year int, month time.Month) string {
+	return path.Join(urlpath, strconv.Itoa(year), fmt.Sprintf("%02d", month))
+}
+
+func tagURL(urlpath, tag string) string {
+	return path.Join(urlpath, "tag", seal.MakeSlug(tag))
+}
+
+// archiveLinks returns links to year and month archives, newest first.
+// Each year link is followed by its month links.
+func archiveLinks(urlpath string, previews []postPreview) []archiveLink {
+	var links []archiveLink
+	var yearIndex int
+	for _, p := range previews { // previews are sorted newest first
+		year, month := p.date.Year(), p.date.Month()
+		if len(links) == 0 || links[yearIndex].Year != year {
+			links = append(links, archiveLink{Year: year, URL: yearURL(urlpath, year)})
+			yearIndex = len(links) - 1
+		}
+		links[yearIndex].Count++
+		if last := links[len(links)-1]; last.Month != month {
+			links = append(links, archiveLink{Year: year, Month: month, URL: monthURL(urlpath, year, month)})
+		}
+		links[len(links)-1].Count++
+	}
+	return links
+}
+
+// tagLinks returns links to all tags of the previews, sorted by name.
+func tagLinks(urlpath string, previews []postPreview) []tagLink {
+	var names []string
+	for _, p := range previews {
+		for _, tag := range p.Tags {
+			if !slices.ContainsFunc(names, func(name string) bool { return seal.MakeSlug(name) == seal.MakeSlug(tag.Name) }) {
+				names = append(names, tag.Name)
+			}
+		}
+	}
+	slices.Sort(names)
+
+	var links []tagLink
+	for _, name := range names {
+		links = append(links, tagLink{Name: name, URL: tagURL(urlpath, name)})
+	}
+	return links
+}
+
+func filterPreviews(previews []postPreview, keep func(postPreview) bool) []postPreview {
+	var result []postPreview
+	for _, p := range previews {
+		if keep(p) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
@@ -0,0 +1,200 @@
+package miniblog
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/wansing/seal"
+)
+
+// feedEntry is a post, prepared for feeds.
+type feedEntry struct {
+	Title     string
+	URLPath   string
+	Published time.Time
+	Updated   time.Time
+	Summary   string
+	HTML      string // rendered post
+}
+
+type feed struct {
+	Title     string
+	Author    string // required by Atom, as entries have no author
+	URLPath   string // of the blog
+	Entries   []feedEntry
+	Generated time.Time // when the blog has been read
+}
+
+// updated returns the most recent update of an entry, or f.Generated if there are no entries.
+func (f feed) updated() time.Time {
+	var updated time.Time
+	for _, entry := range f.Entries {
+		if entry.Updated.After(updated) {
+			updated = entry.Updated
+		}
+	}
+	if updated.IsZero() {
+		return f.Generated
+	}
+	return updated
+}
+
+// FeedLinks returns <link rel="alternate"> elements for the feeds of the blog at urlpath, for use in the HTML head.
+// Within the blog templates, it is available as template function "feedLinks".
+func (mb *Miniblog) FeedLinks(urlpath, title string) template.HTML {
+	base := strings.TrimSuffix(mb.BaseURL, "/") // relative if not configured
+	return template.HTML(fmt.Sprintf(
+		`<link rel="alternate" type="application/atom+xml" title="%s" href="%s">`+"\n"+
+			`<link rel="alternate" type="application/rss+xml" title="%s" href="%s">`,
+		template.HTMLEscapeString(title),
+		template.HTMLEscapeString(base+urlpath+"/feed.xml"),
+		template.HTMLEscapeString(title),
+		template.HTMLEscapeString(base+urlpath+"/rss.xml"),
+	))
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Author  atomPerson  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title     string    `xml:"title"`
+	ID        string    `xml:"id"`
+	Link      atomLink  `xml:"link"`
+	Published string    `xml:"published"`
+	Updated   string    `xml:"updated"`
+	Summary   *atomText `xml:"summary,omitempty"`
+	Content   *atomText `xml:"content,omitempty"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+func (mb *Miniblog) atomHandler(f feed) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		base := seal.RequestBaseURL(mb.BaseURL, r)
+		atom := atomFeed{
+			Title:   f.Title,
+			ID:      base + f.URLPath + "/",
+			Updated: f.updated().Format(time.RFC3339),
+			Author:  atomPerson{Name: f.Author},
+			Links: []atomLink{
+				{Href: base + f.URLPath + "/"},
+				{Href: base + f.URLPath + "/feed.xml", Rel: "self", Type: "application/atom+xml"},
+			},
+		}
+		for _, entry := range f.Entries {
+			e := atomEntry{
+				Title:     entry.Title,
+				ID:        base + entry.URLPath,
+				Link:      atomLink{Href: base + entry.URLPath},
+				Published: entry.Published.Format(time.RFC3339),
+				Updated:   entry.Updated.Format(time.RFC3339),
+			}
+			if entry.Summary != "" {
+				e.Summary = &atomText{Type: "text", Body: entry.Summary}
+			}
+			if mb.FullContent {
+				e.Content = &atomText{Type: "html", Body: absURLs(entry.HTML, base)}
+			}
+			atom.Entries = append(atom.Entries, e)
+		}
+		writeXML(w, "application/atom+xml", atom)
+	}
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Description string  `xml:"description,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func (mb *Miniblog) rssHandler(f feed) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		base := seal.RequestBaseURL(mb.BaseURL, r)
+		rss := rssFeed{
+			Version: "2.0",
+			Channel: rssChannel{
+				Title:       f.Title,
+				Link:        base + f.URLPath + "/",
+				Description: f.Title,
+			},
+		}
+		if updated := f.updated(); !updated.IsZero() {
+			rss.Channel.LastBuildDate = updated.Format(time.RFC1123Z)
+		}
+		for _, entry := range f.Entries {
+			item := rssItem{
+				Title:       entry.Title,
+				Link:        base + entry.URLPath,
+				GUID:        rssGUID{IsPermaLink: true, Value: base + entry.URLPath},
+				PubDate:     entry.Published.Format(time.RFC1123Z),
+				Description: entry.Summary,
+			}
+			if mb.FullContent {
+				item.Description = absURLs(entry.HTML, base)
+			}
+			rss.Channel.Items = append(rss.Channel.Items, item)
+		}
+		writeXML(w, "application/rss+xml", rss)
+	}
+}
+
+var rootRelative = regexp.MustCompile(`(href|src)="/([^/])`) // but not protocol-relative
+
+// absURLs prepends base to root-relative href and src attributes. They have been made root-relative by content.AbsHrefSrc before.
+func absURLs(htm, base string) string {
+	return rootRelative.ReplaceAllString(htm, `$1="`+base+`/$2`)
+}
+
+func writeXML(w http.ResponseWriter, contentType string, v any) {
+	w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+	w.Write([]byte(xml.Header))
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	enc.Encode(v)
+}
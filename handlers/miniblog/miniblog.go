@@ -1,13 +1,23 @@
 package miniblog
 
 import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
 	"html/template"
 	"io/fs"
+	"maps"
 	"net/http"
+	"net/url"
 	"path"
 	"regexp"
 	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/wansing/seal"
 	"github.com/wansing/seal/content"
@@ -16,8 +26,99 @@ import (
 
 var isoDate = regexp.MustCompile("[0-9]{4}-[0-9]{2}-[0-9]{2}")
 
+// A Miniblog can serve multiple blogs. It keeps a registry of their previews for Latest.
+// Add it to seal.Server.Publishers, so the registry is swapped together with the snapshot.
 type Miniblog struct {
-	previews []postPreview
+	BaseURL     string // like "https://example.com", for absolute URLs in feeds, derived from the request if empty
+	Author      string // author of the feeds, unless index.html has an "author" key, default is the blog title
+	FullContent bool   // include the rendered posts in feeds, not just summaries
+	PageSize    int    // posts per index page, default 10
+	Lang        string // language of the built-in labels, like the first of seal.Server.Languages, unless index.html has a "lang" key
+
+	lock  sync.Mutex // serializes publishing
+	blogs atomic.Pointer[registry]
+}
+
+// registry holds the previews of all blogs of a snapshot generation.
+type registry struct {
+	generation int
+	previews   map[string][]postPreview // key is urlpath of the blog
+}
+
+// Publish implements seal.Publisher. It drops the registry of previous generations, even if there is no blog in the new generation.
+func (mb *Miniblog) Publish(generation int) {
+	mb.lock.Lock()
+	defer mb.lock.Unlock()
+	if r := mb.blogs.Load(); r == nil || r.generation != generation {
+		mb.blogs.Store(&registry{generation: generation})
+	}
+}
+
+func (mb *Miniblog) publishBlog(generation int, urlpath string, previews []postPreview) {
+	mb.lock.Lock()
+	defer mb.lock.Unlock()
+	var next = &registry{
+		generation: generation,
+		previews:   make(map[string][]postPreview),
+	}
+	if r := mb.blogs.Load(); r != nil && r.generation == generation {
+		maps.Copy(next.previews, r.previews) // copy on write
+	}
+	next.previews[urlpath] = previews
+	mb.blogs.Store(next)
+}
+
+// previews returns the previews of the blog at urlpath, or of all blogs if urlpath is empty, newest first.
+func (mb *Miniblog) previews(urlpath string) []postPreview {
+	r := mb.blogs.Load()
+	if r == nil {
+		return nil
+	}
+	if urlpath != "" {
+		return r.previews[urlpath]
+	}
+	var all []postPreview
+	for _, previews := range r.previews {
+		all = append(all, previews...)
+	}
+	slices.SortStableFunc(all, func(a, b postPreview) int {
+		return cmp.Or(b.date.Compare(a.date), strings.Compare(b.URL, a.URL))
+	})
+	return all
+}
+
+// blogHandler implements seal.Publisher, so the previews are swapped together with the snapshot,
+// seal.DocumentLister, so the blog can be exported and its posts can be searched,
+// and seal.LastModifier for the sitemap.
+type blogHandler struct {
+	*http.ServeMux
+	mb        *Miniblog
+	urlpath   string
+	previews  []postPreview
+	urlpaths  []string
+	postPaths []string
+	feedPaths []string
+	lastMods  map[string]time.Time // posts and blog root
+}
+
+func (h *blogHandler) Publish(generation int) {
+	h.mb.publishBlog(generation, h.urlpath, h.previews)
+}
+
+func (h *blogHandler) URLPaths() []string {
+	return h.urlpaths
+}
+
+func (h *blogHandler) DocumentURLPaths() []string {
+	return h.postPaths
+}
+
+func (h *blogHandler) FeedURLPaths() []string {
+	return h.feedPaths
+}
+
+func (h *blogHandler) LastModified(urlpath string) time.Time {
+	return h.lastMods[urlpath]
 }
 
 type postPreview struct {
@@ -25,22 +126,36 @@ type postPreview struct {
 	Date   string
 	Title  string
 	URL    string
-}
-
-type IndexData struct {
-	seal.TemplateData
-	Previews []postPreview
+	Tags   []tagLink
+	date   time.Time
 }
 
 type PostData struct {
 	seal.TemplateData
 	BackURL string
 	Date    string
+	Tags    []tagLink
 }
 
+// Latest lists the latest posts. The first line of the filecontent can contain the URL path of the blog and the maximum number of posts,
+// like "/news 5". Without the URL path, posts of all blogs are listed. The remaining filecontent is used as template.
 func (mb *Miniblog) Latest(t *template.Template, urlpath, fileroot string, filecontent []byte) error {
 	var text = string(filecontent)
-	if text == "" {
+	var blog string
+	var limit int
+	if header, rest, _ := strings.Cut(text, "\n"); strings.HasPrefix(header, "/") {
+		fields := strings.Fields(header)
+		blog = path.Clean(fields[0])
+		if len(fields) > 1 {
+			var err error
+			if limit, err = strconv.Atoi(fields[1]); err != nil {
+				return fmt.Errorf("parsing number of posts: %w", err)
+			}
+		}
+		text = rest
+	}
+
+	if strings.TrimSpace(text) == "" {
 		text = `<ul>
 			{{range .}}
 				<li id="{{.Anchor}}">
@@ -54,106 +169,379 @@ func (mb *Miniblog) Latest(t *template.Template, urlpath, fileroot string, filec
 		t,
 		text,
 		func() []postPreview {
-			return mb.previews
+			previews := mb.previews(blog)
+			if limit > 0 && len(previews) > limit {
+				previews = previews[:limit]
+			}
+			return previews
 		},
 	)
 }
 
-func readTmpl(t *template.Template, fsys fs.FS, filename string, defaultText string) *template.Template {
-	var text = defaultText
+// readTmpl parses the file (or defaultText if it does not exist) as "main" template into a clone of t and returns its front matter.
+func readTmpl(t *template.Template, fsys fs.FS, filename string, defaultText string, funcs template.FuncMap) (*template.Template, content.Meta, error) {
+	var text = []byte(defaultText)
 	if fileText, err := fs.ReadFile(fsys, filename); err == nil {
-		text = string(fileText)
+		text = fileText
+	}
+	meta, text, err := content.SplitFrontMatter(text)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	t, err = t.Clone()
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := t.New("main").Funcs(funcs).Parse(string(text)); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return t, meta, nil
+}
+
+// serve executes tmpl into a buffer, so a failure results in the error page instead of partial output.
+func serve(w http.ResponseWriter, r *http.Request, tmpl *template.Template, data any) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		seal.ServeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+type post struct {
+	fileroot    string
+	date        time.Time
+	meta        content.Meta
+	body        []byte // without front matter
+	contentFunc seal.ContentFunc
+}
+
+// readPosts returns the posts in fsys, newest first. Posts are content files whose front matter contains a date or whose name starts with an ISO date.
+// Drafts are skipped. Files which can't be read are skipped and reported in the returned error.
+func readPosts(fsys fs.FS, contentFuncs map[string]seal.ContentFunc) ([]post, error) {
+	var posts []post
+	var errs []error
+	entries, _ := fs.ReadDir(fsys, ".")
+	for _, entry := range entries {
+		if entry.IsDir() || slices.Contains(templateFiles, entry.Name()) {
+			continue
+		}
+		ext := path.Ext(entry.Name())
+		contentFunc, ok := contentFuncs[ext]
+		if !ok {
+			continue
+		}
+		filecontent, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		meta, body, err := content.SplitFrontMatter(filecontent)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Name(), err))
+			continue
+		}
+		if meta.Draft() {
+			continue
+		}
+
+		fileroot := strings.TrimSuffix(entry.Name(), ext)
+		date := meta.Date()
+		if date.IsZero() && len(fileroot) >= 10 && isoDate.MatchString(fileroot[:10]) {
+			date, _ = content.ParseDate(fileroot[:10])
+		}
+		if date.IsZero() {
+			continue
+		}
+
+		posts = append(posts, post{
+			fileroot:    fileroot,
+			date:        date,
+			meta:        meta,
+			body:        body,
+			contentFunc: contentFunc,
+		})
 	}
-	t, _ = t.Clone()
-	t.New("main").Parse(text)
-	return t
+	slices.SortStableFunc(posts, func(a, b post) int {
+		return cmp.Or(b.date.Compare(a.date), strings.Compare(b.fileroot, a.fileroot)) // newest first
+	})
+	return posts, errors.Join(errs...)
 }
 
-// MakeHandler reads index.html and post.html (if exist) as "main" templates for index and post views.
-func (mb *Miniblog) MakeHandler(fsys fs.FS, urlpath string, t *template.Template, contentFuncs map[string]seal.ContentFunc) http.Handler {
-	indexTmpl := readTmpl(
+// templateFiles are not posts
+var templateFiles = []string{"index.html", "post.html", "archive.html", "tag.html"}
+
+// MakeHandler reads index.html, post.html, archive.html and tag.html (if exist) as "main" templates for index, post, archive and tag views.
+// The index is paginated at page/2 etc. Archives are served at 2006 and 2006/01, tags at tag/name.
+// Tags are read from the front matter of the posts.
+// The front matter title of index.html is used as blog title, its "lang" key sets the language of the built-in labels instead of mb.Lang,
+// and its "author" key sets the author of the feeds instead of mb.Author.
+// It also serves an Atom feed at feed.xml and an RSS 2.0 feed at rss.xml.
+// In the templates, {{feedLinks}} returns <link rel="alternate"> elements for these feeds.
+//
+// Errors in templates are returned. Errors in posts are returned too, but the affected posts are skipped and the handler is still usable.
+func (mb *Miniblog) MakeHandler(fsys fs.FS, urlpath string, t *template.Template, contentFuncs map[string]seal.ContentFunc) (http.Handler, error) {
+	var title = path.Base(urlpath)
+	var funcs = template.FuncMap{
+		"feedLinks": func() template.HTML {
+			return mb.FeedLinks(urlpath, title)
+		},
+	}
+
+	indexTmpl, indexMeta, err := readTmpl(
 		t,
 		fsys,
 		"index.html",
-		`<ul>
-			{{range .Previews}}
-				<li id="{{.Anchor}}">
-					<a href="{{.URL}}">{{.Date}} {{.Title}}</a>
-				</li>
-			{{end}}
-		</ul>`,
+		previewList+`
+		<p>
+			{{with .PrevURL}}<a href="{{.}}">{{$.T "Newer posts"}}</a>{{end}}
+			{{with .NextURL}}<a href="{{.}}">{{$.T "Older posts"}}</a>{{end}}
+		</p>`,
+		funcs,
 	)
+	if err != nil {
+		return nil, err
+	}
+	if indexMeta.Title() != "" {
+		title = indexMeta.Title()
+	}
+	var lang = cmp.Or(indexMeta.String("lang"), mb.Lang)
 
-	postTmpl := readTmpl(
+	postTmpl, _, err := readTmpl(
 		t,
 		fsys,
 		"post.html",
-		`<p><a href="{{.BackURL}}">Back to Blog</a></p>
-		<p>{{.Date}}</p>
+		`<p><a href="{{.BackURL}}">{{$.T "Back to Blog"}}</a></p>
+		<p>{{.Date}}{{range .Tags}} <a href="{{.URL}}">#{{.Name}}</a>{{end}}</p>
 		{{template "post" .}}`,
+		funcs,
 	)
+	if err != nil {
+		return nil, err
+	}
 
-	var postFilenames []string
-	entries, _ := fs.ReadDir(fsys, ".")
-	for _, entry := range entries {
-		if !entry.IsDir() && len(entry.Name()) >= 10 && isoDate.MatchString(entry.Name()[:10]) {
-			postFilenames = append(postFilenames, entry.Name())
-		}
+	archiveTmpl, _, err := readTmpl(
+		t,
+		fsys,
+		"archive.html",
+		`<p><a href="{{.BackURL}}">{{$.T "Back to Blog"}}</a></p>
+		<h1>{{.Title}}</h1>
+		`+previewList,
+		funcs,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	tagTmpl, _, err := readTmpl(
+		t,
+		fsys,
+		"tag.html",
+		`<p><a href="{{.BackURL}}">{{$.T "Back to Blog"}}</a></p>
+		<h1>#{{.Tag}}</h1>
+		`+previewList,
+		funcs,
+	)
+	if err != nil {
+		return nil, err
 	}
-	slices.Sort(postFilenames)
-	slices.Reverse(postFilenames)
+
+	posts, err := readPosts(fsys, contentFuncs)
+	var errs = []error{err}
 
 	var mux = http.NewServeMux()
+	var urlpaths []string
+	var postPaths []string
+	var lastMods = make(map[string]time.Time)
 	var previews []postPreview
+	var feedEntries []feedEntry
 
-	mux.HandleFunc("GET "+urlpath+"/", func(w http.ResponseWriter, r *http.Request) {
-		indexTmpl.Execute(w, IndexData{
+	// handle registers h unless pattern has been registered before, e.g. if a post is named like an archive
+	handle := func(urlpath string, h http.HandlerFunc) {
+		if slices.Contains(urlpaths, urlpath) {
+			errs = append(errs, fmt.Errorf("%s: duplicate URL path", urlpath))
+			return
+		}
+		urlpaths = append(urlpaths, urlpath)
+		mux.HandleFunc(seal.MuxPattern(http.MethodGet, urlpath, true), h) // file names can contain "{" or spaces
+	}
+
+	for _, p := range posts {
+		fileroot := p.fileroot
+		date := p.date.Format(time.DateOnly)
+		postURLPath := path.Join(urlpath, fileroot)
+		postData := PostData{
 			TemplateData: seal.TemplateData{
-				RequestURL: r.URL,
-				URLPath:    urlpath,
+				RequestURL: &url.URL{Path: postURLPath},
+				URLPath:    postURLPath,
+				Meta:       p.meta,
+				Lang:       cmp.Or(p.meta.String("lang"), lang),
 			},
-			Previews: previews,
-		})
-	})
-
-	for _, fn := range postFilenames {
-		contentFunc, ok := contentFuncs[path.Ext(fn)]
-		if !ok {
-			continue
+			BackURL: urlpath + "#" + seal.MakeSlug(fileroot),
+			Date:    date,
+		}
+		for _, tag := range p.meta.Tags() {
+			postData.Tags = append(postData.Tags, tagLink{Name: tag, URL: tagURL(urlpath, tag)})
 		}
-		filecontent, _ := fs.ReadFile(fsys, fn)
-		fileroot := strings.TrimSuffix(fn, path.Ext(fn))
-		date := fileroot[:10]
 
 		tmpl, _ := postTmpl.Clone()
-		_ = contentFunc(tmpl.New("post"), urlpath, fileroot, filecontent)
+		if err := p.contentFunc(tmpl.New("post"), urlpath, fileroot, p.body); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", fileroot, err))
+			continue
+		}
 
 		// for blog index
-		var title = handlers.Heading(tmpl.Lookup("post"))
-		if title == "" {
-			title = fileroot
+		var postTitle = p.meta.Title()
+		if postTitle == "" {
+			postTitle = handlers.Heading(tmpl.Lookup("post"))
+		}
+		if postTitle == "" {
+			postTitle = fileroot
 		}
 		previews = append(previews, postPreview{
 			Anchor: fileroot,
 			Date:   date,
-			Title:  title,
-			URL:    path.Join(urlpath, fileroot),
+			Title:  postTitle,
+			URL:    postURLPath,
+			Tags:   postData.Tags,
+			date:   p.date,
+		})
+
+		// for feeds
+		var rendered bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&rendered, "post", postData); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", fileroot, err))
+		}
+		var summary = p.meta.Description()
+		if summary == "" {
+			summary = handlers.Summary(rendered.Bytes())
+		}
+		var updated = p.date
+		if u, err := content.ParseDate(p.meta.String("updated")); err == nil {
+			updated = u
+		}
+		lastMods[postURLPath] = updated
+		if updated.After(lastMods[pageURL(urlpath, 1)]) {
+			lastMods[pageURL(urlpath, 1)] = updated
+		}
+		feedEntries = append(feedEntries, feedEntry{
+			Title:     postTitle,
+			URLPath:   postURLPath,
+			Published: p.date,
+			Updated:   updated,
+			Summary:   summary,
+			HTML:      rendered.String(),
+		})
+
+		postPaths = append(postPaths, postURLPath)
+		handle(postURLPath, func(w http.ResponseWriter, r *http.Request) {
+			data := postData // copy
+			data.RequestURL = r.URL
+			serve(w, r, tmpl, data)
+		})
+	}
+
+	// index pages
+	var pageSize = mb.PageSize
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	var archive = archiveLinks(urlpath, previews)
+	var tags = tagLinks(urlpath, previews)
+	var pages = paginate(previews, pageSize)
+	for i, pagePreviews := range pages {
+		data := IndexData{
+			TemplateData: seal.TemplateData{
+				URLPath: urlpath,
+				Meta:    indexMeta,
+				Lang:    lang,
+			},
+			Previews: pagePreviews,
+			Page:     i + 1,
+			Pages:    len(pages),
+			Archive:  archive,
+			Tags:     tags,
+		}
+		if i > 0 {
+			data.PrevURL = pageURL(urlpath, i)
+		}
+		if i < len(pages)-1 {
+			data.NextURL = pageURL(urlpath, i+2)
+		}
+		handle(pageURL(urlpath, i+1), func(w http.ResponseWriter, r *http.Request) {
+			data := data // copy, because concurrent requests must not modify the captured variable
+			data.RequestURL = r.URL
+			serve(w, r, indexTmpl, data)
+		})
+	}
+
+	// archives
+	for _, link := range archive {
+		data := ArchiveData{
+			TemplateData: seal.TemplateData{
+				URLPath: link.URL,
+				Lang:    lang,
+			},
+			BackURL: urlpath + "/",
+			Year:    link.Year,
+			Month:   link.Month,
+			Previews: filterPreviews(previews, func(p postPreview) bool {
+				return p.date.Year() == link.Year && (link.Month == 0 || p.date.Month() == link.Month)
+			}),
+		}
+		if link.Month == 0 {
+			data.Title = strconv.Itoa(link.Year)
+		} else {
+			data.Title = fmt.Sprintf("%d-%02d", link.Year, link.Month)
+		}
+		handle(link.URL, func(w http.ResponseWriter, r *http.Request) {
+			data := data // copy
+			data.RequestURL = r.URL
+			serve(w, r, archiveTmpl, data)
 		})
+	}
 
-		mux.HandleFunc("GET "+path.Join(urlpath, fileroot), func(w http.ResponseWriter, r *http.Request) {
-			tmpl.Execute(w, PostData{
-				TemplateData: seal.TemplateData{
-					RequestURL: r.URL,
-					URLPath:    path.Join(urlpath, fileroot),
-				},
-				BackURL: urlpath + "#" + seal.MakeSlug(fileroot),
-				Date:    date,
+	// tags
+	for _, tag := range tags {
+		data := TagData{
+			TemplateData: seal.TemplateData{
+				URLPath: tag.URL,
+				Lang:    lang,
 			},
-			)
+			BackURL: urlpath + "/",
+			Tag:     tag.Name,
+			Previews: filterPreviews(previews, func(p postPreview) bool {
+				return slices.ContainsFunc(p.Tags, func(t tagLink) bool { return t.URL == tag.URL })
+			}),
+		}
+		handle(tag.URL, func(w http.ResponseWriter, r *http.Request) {
+			data := data // copy
+			data.RequestURL = r.URL
+			serve(w, r, tagTmpl, data)
 		})
 	}
 
-	mb.previews = previews
+	// feeds
+	var f = feed{
+		Title:     title,
+		Author:    cmp.Or(indexMeta.String("author"), mb.Author, title),
+		URLPath:   urlpath,
+		Entries:   feedEntries,
+		Generated: time.Now(),
+	}
+	var feedPaths = []string{urlpath + "/feed.xml", urlpath + "/rss.xml"}
+	handle(feedPaths[0], mb.atomHandler(f))
+	handle(feedPaths[1], mb.rssHandler(f))
 
-	return mux
+	return &blogHandler{
+		ServeMux:  mux,
+		mb:        mb,
+		urlpath:   urlpath,
+		previews:  previews,
+		urlpaths:  urlpaths,
+		postPaths: postPaths,
+		feedPaths: feedPaths,
+		lastMods:  lastMods,
+	}, errors.Join(errs...)
 }
@@ -56,3 +56,31 @@ func heading(htm []byte) string {
 
 	return ""
 }
+
+// Summary returns the text of the first paragraph in htm, without markup.
+func Summary(htm []byte) string {
+	var tokenizer = html.NewTokenizerFragment(bytes.NewReader(htm), "body")
+	var inParagraph = false
+	var result = &strings.Builder{}
+
+	for {
+		tt := tokenizer.Next()
+		if tt == html.ErrorToken {
+			break // assuming tokenizer.Err() == io.EOF
+		}
+
+		tagNameBytes, _ := tokenizer.TagName()
+		tagName := string(tagNameBytes)
+
+		switch {
+		case !inParagraph && tt == html.StartTagToken && tagName == "p":
+			inParagraph = true
+		case inParagraph && tt == html.EndTagToken && tagName == "p":
+			return strings.TrimSpace(result.String())
+		case inParagraph && tt == html.TextToken:
+			result.Write(tokenizer.Text())
+		}
+	}
+
+	return strings.TrimSpace(result.String())
+}
@@ -0,0 +1,150 @@
+package seal
+
+import (
+	"cmp"
+	"html/template"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/wansing/seal/content"
+)
+
+// A Translation links a page to one of its language variants.
+type Translation struct {
+	Lang string
+	URL  string // URL path, or absolute URL if Server.BaseURL is set
+}
+
+// T returns the translation of the English message msg into data.Lang, see content.Message.
+func (data TemplateData) T(msg string) string {
+	return content.Message(data.Lang, msg)
+}
+
+// AlternateLinks returns <link rel="alternate" hreflang="..."> elements for the language variants of the page, for the HTML head.
+func (data TemplateData) AlternateLinks() template.HTML {
+	if len(data.Translations) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, t := range data.Translations {
+		b.WriteString(`<link rel="alternate" hreflang="` + template.HTMLEscapeString(t.Lang) + `" href="` + template.HTMLEscapeString(t.URL) + `">`)
+	}
+	b.WriteString(`<link rel="alternate" hreflang="x-default" href="` + template.HTMLEscapeString(data.defaultURL) + `">`)
+	return template.HTML(b.String())
+}
+
+// splitLang splits a language suffix like ".de" off fileroot, if it is one of srv.Languages.
+func (srv *Server) splitLang(fileroot string) (base, lang string) {
+	if ext := path.Ext(fileroot); ext != "" && slices.Contains(srv.Languages, ext[1:]) {
+		return strings.TrimSuffix(fileroot, ext), ext[1:]
+	}
+	return fileroot, ""
+}
+
+// langURLPath returns the URL path of the language variant of the page in the directory urlpath, like "/de/about".
+func (srv *Server) langURLPath(lang, urlpath string) string {
+	return srv.pageURLPath(path.Join("/", lang, urlpath))
+}
+
+// absURL prepends srv.BaseURL to urlpath.
+func (srv *Server) absURL(urlpath string) string {
+	return strings.TrimSuffix(srv.BaseURL, "/") + urlpath
+}
+
+// langTmpl returns t, or a clone of t in which each template "name.lang" of variants replaces "name".
+// If the clone contains an "html" template, it is returned.
+func langTmpl(t *template.Template, lang string, variants map[string]bool) *template.Template {
+	var names []string
+	for name := range variants {
+		if strings.HasSuffix(name, "."+lang) && t.Lookup(name) != nil {
+			names = append(names, name)
+		}
+	}
+	if lang == "" || len(names) == 0 {
+		return t
+	}
+	clone, err := t.Clone()
+	if err != nil {
+		return t
+	}
+	for _, name := range names {
+		clone.New(strings.TrimSuffix(name, "."+lang)).Parse(`{{template "` + name + `" .}}`)
+	}
+	if html := clone.Lookup("html"); html != nil {
+		return html
+	}
+	return clone.Lookup(t.Name()) // Lookup, because clone is stale if it has been redefined
+}
+
+// variantName returns the name of the template which replaces the template name in the language lang, see langTmpl.
+func variantName(name, lang string, variants map[string]bool) string {
+	if variants[name+"."+lang] {
+		return name + "." + lang
+	}
+	return name
+}
+
+// dirTemplates returns the names of templates which apply in a directory, like language variants or dynamic templates:
+// the inherited ones, unless the directory redefines them or their base template, and the local ones.
+func (srv *Server) dirTemplates(inherited map[string]bool, pg *page, local []string) map[string]bool {
+	var names = make(map[string]bool)
+	for name := range inherited {
+		if base, _ := srv.splitLang(name); !slices.Contains(pg.bases, base) && !slices.Contains(pg.variants, name) {
+			names[name] = true
+		}
+	}
+	for _, name := range local {
+		names[name] = true
+	}
+	return names
+}
+
+// negotiateHandler serves the language variant which matches the Accept-Language header of the request best.
+// langs must not be empty. Its first entry is the fallback.
+func negotiateHandler(langs []string, handlers map[string]http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Language")
+		handlers[acceptLanguage(r.Header.Get("Accept-Language"), langs)].ServeHTTP(w, r)
+	}
+}
+
+// acceptLanguage returns the entry of langs which matches the Accept-Language header best, or the first entry of langs.
+// A language like "de-AT" matches "de" too.
+func acceptLanguage(header string, langs []string) string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+	var accepted []weighted
+	for _, item := range strings.Split(header, ",") {
+		lang, params, _ := strings.Cut(item, ";")
+		var q = 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q, _ = strconv.ParseFloat(v, 64)
+		}
+		if lang = strings.ToLower(strings.TrimSpace(lang)); lang != "" && q > 0 {
+			accepted = append(accepted, weighted{lang, q})
+		}
+	}
+	slices.SortStableFunc(accepted, func(a, b weighted) int {
+		return cmp.Compare(b.q, a.q)
+	})
+	for _, a := range accepted {
+		for tag := a.lang; ; {
+			for _, lang := range langs {
+				if strings.ToLower(lang) == tag {
+					return lang
+				}
+			}
+			i := strings.LastIndex(tag, "-")
+			if i < 0 {
+				break
+			}
+			tag = tag[:i]
+		}
+	}
+	return langs[0]
+}
@@ -0,0 +1,186 @@
+package seal
+
+import (
+	"cmp"
+	"html/template"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/wansing/seal/content"
+	"github.com/wansing/seal/handlers"
+)
+
+// A NavNode is a directory in the navigation tree. The tree is built by readDir and not modified afterwards.
+//
+// Templates get the node of their directory from the template function "nav", e.g.
+//
+//	{{range nav.Root.Children}}<a href="{{.URLPath}}" {{if .IsActive $.URLPath}}class="active"{{end}}>{{.Title}}</a>{{end}}
+type NavNode struct {
+	URLPath  string
+	Slug     string
+	Title    string // from the metadata file, front matter, first heading of the "main" template or directory name
+	Order    int    // from the metadata file or the numeric prefix of the directory name, zero if none
+	Page     bool   // has its own page, else it is listed because of its children
+	Parent   *NavNode
+	Children []*NavNode // ordered ones first, then the others by name
+
+	ordered      bool
+	hidden       bool
+	translations map[string]navTranslation // key is language
+}
+
+// Root returns the root node of the tree.
+func (n *NavNode) Root() *NavNode {
+	for n.Parent != nil {
+		n = n.Parent
+	}
+	return n
+}
+
+// Breadcrumbs returns the path from the root node to n, including both.
+func (n *NavNode) Breadcrumbs() []*NavNode {
+	var result []*NavNode
+	for ; n != nil; n = n.Parent {
+		result = append([]*NavNode{n}, result...)
+	}
+	return result
+}
+
+// Siblings returns the children of the parent node, including n. The root node has no siblings.
+func (n *NavNode) Siblings() []*NavNode {
+	if n.Parent == nil {
+		return nil
+	}
+	return n.Parent.Children
+}
+
+// IsCurrent returns whether urlpath belongs to the node itself.
+func (n *NavNode) IsCurrent(urlpath string) bool {
+	return strings.TrimSuffix(urlpath, "/") == strings.TrimSuffix(n.URLPath, "/")
+}
+
+// IsActive returns whether urlpath belongs to the node or one of its descendants, e.g. for highlighting menu items.
+func (n *NavNode) IsActive(urlpath string) bool {
+	return n.IsCurrent(urlpath) || strings.HasPrefix(urlpath, strings.TrimSuffix(n.URLPath, "/")+"/")
+}
+
+type navTranslation struct {
+	urlpath string
+	title   string
+}
+
+// URLPathIn returns the URL path of the language variant of the page, or URLPath if there is none.
+func (n *NavNode) URLPathIn(lang string) string {
+	if t, ok := n.translations[lang]; ok {
+		return t.urlpath
+	}
+	return n.URLPath
+}
+
+// TitleIn returns the title of the language variant of the page, or Title if there is none.
+func (n *NavNode) TitleIn(lang string) string {
+	if t := n.translations[lang].title; t != "" {
+		return t
+	}
+	return n.Title
+}
+
+func (n *NavNode) addTranslation(lang, urlpath, title string) {
+	if n.translations == nil {
+		n.translations = make(map[string]navTranslation)
+	}
+	n.translations[lang] = navTranslation{urlpath: urlpath, title: title}
+}
+
+// navFuncs sets the template function "nav" of t to return n.
+// Clones of t keep it until it is set again.
+func navFuncs(t *template.Template, n *NavNode) {
+	t.Funcs(template.FuncMap{
+		"nav": func() *NavNode {
+			return n
+		},
+	})
+}
+
+// addChild sets n as parent of child and adds child to the children of n, unless it is hidden or has neither a page nor children.
+func (n *NavNode) addChild(child *NavNode) {
+	child.Parent = n // breadcrumbs of hidden pages work too
+	if child.hidden || (!child.Page && len(child.Children) == 0) {
+		return
+	}
+	n.Children = append(n.Children, child)
+	slices.SortStableFunc(n.Children, func(a, b *NavNode) int {
+		switch {
+		case a.ordered && b.ordered:
+			return cmp.Compare(a.Order, b.Order)
+		case a.ordered:
+			return -1
+		case b.ordered:
+			return 1
+		default:
+			return 0 // keep name order
+		}
+	})
+}
+
+// dirMetaFile is the metadata file of a directory. It contains "key: value" lines like a front matter block.
+// Keys are "title", "order", "hidden" (from navigation) and "redirect" (URL or path which the directory redirects to).
+const dirMetaFile = ".meta"
+
+// readDirMeta reads the metadata file in fspath, if it exists.
+func (srv *Server) readDirMeta(snap *snapshot, fspath, urlpath string) content.Meta {
+	data, err := fs.ReadFile(srv.FS, path.Join(fspath, dirMetaFile))
+	if err != nil {
+		return nil // not existing
+	}
+	meta, err := content.ParseMeta(data)
+	if err != nil {
+		snap.log(SeverityError, err, urlpath, path.Join(fspath, dirMetaFile))
+	}
+	return meta
+}
+
+// apply sets the title, order and hidden flag of n from the metadata file.
+func (n *NavNode) apply(dirMeta content.Meta) {
+	if title := dirMeta.Title(); title != "" {
+		n.Title = title
+	}
+	if order, ok := dirMeta.Int("order"); ok {
+		n.Order, n.ordered = order, true
+	}
+	n.hidden = dirMeta.Bool("hidden")
+}
+
+var orderPrefix = regexp.MustCompile(`^([0-9]+)-(.+)$`)
+
+// newNavNode returns a node for the directory name in parent. A numeric prefix like "01-" is removed from the slug and used as order.
+func newNavNode(parentURLPath, name string) *NavNode {
+	var n = &NavNode{
+		Title: name,
+	}
+	if m := orderPrefix.FindStringSubmatch(name); m != nil {
+		n.Order, _ = strconv.Atoi(m[1])
+		n.ordered = true
+		n.Title = m[2]
+	}
+	n.Slug = MakeSlug(n.Title)
+	n.URLPath = path.Join(parentURLPath, n.Slug)
+	return n
+}
+
+// navTitle returns the title of a page. The name of the "main" template might have a language suffix, see variantName.
+func navTitle(tmpl *template.Template, mainName, title, fallback string) string {
+	if title != "" {
+		return title
+	}
+	if main := tmpl.Lookup(mainName); main != nil && main.Tree != nil {
+		if heading := handlers.Heading(main); heading != "" {
+			return heading
+		}
+	}
+	return fallback
+}
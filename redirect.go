@@ -0,0 +1,119 @@
+package seal
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// redirectsFile maps old URL paths to new ones. It contains lines like "old new [status]".
+// Relative paths are relative to the directory. The status code defaults to 301.
+// A redirects file in the root directory is site-wide.
+const redirectsFile = ".redirects"
+
+// A redirect is collected by readDir and registered after all routes are known, so conflicts can be detected.
+type redirect struct {
+	From   string // URL path
+	To     string // URL path or absolute URL
+	Status int
+	FSPath string // where it has been declared
+}
+
+// resolveURLPath makes p absolute, relative to the directory urlpath. Absolute URLs are returned as they are.
+func resolveURLPath(urlpath, p string) string {
+	if u, err := url.Parse(p); err == nil && u.Scheme != "" {
+		return p
+	}
+	var trailingSlash = strings.HasSuffix(p, "/")
+	if !path.IsAbs(p) {
+		p = path.Join(urlpath, p)
+	}
+	p = path.Clean(p)
+	if trailingSlash && p != "/" {
+		p += "/"
+	}
+	return p
+}
+
+// readRedirects adds the redirects of the redirects file in fspath, if it exists, to snap.
+func (srv *Server) readRedirects(snap *snapshot, fspath, urlpath string) {
+	fsfile := path.Join(fspath, redirectsFile)
+	data, err := fs.ReadFile(srv.FS, fsfile)
+	if err != nil {
+		return // not existing
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		var status = http.StatusMovedPermanently
+		switch len(fields) {
+		case 2:
+		case 3:
+			status, err = strconv.Atoi(fields[2])
+			if err != nil || (status != http.StatusMovedPermanently && status != http.StatusFound && status != http.StatusTemporaryRedirect && status != http.StatusPermanentRedirect) {
+				snap.log(SeverityError, fmt.Errorf("line %d: invalid status code %q", line, fields[2]), urlpath, fsfile)
+				continue
+			}
+		default:
+			snap.log(SeverityError, fmt.Errorf("line %d: expected old path, new path and optional status code", line), urlpath, fsfile)
+			continue
+		}
+		snap.redirects = append(snap.redirects, redirect{
+			From:   resolveURLPath(urlpath, fields[0]),
+			To:     resolveURLPath(urlpath, fields[1]),
+			Status: status,
+			FSPath: fsfile,
+		})
+	}
+}
+
+// addRedirects registers the redirects of snap. Redirects which conflict with routes, handler directories or other redirects are not registered, and an error is recorded.
+func (srv *Server) addRedirects(snap *snapshot) {
+	for _, rd := range snap.redirects {
+		if err := snap.conflict(rd.From); err != nil {
+			snap.log(SeverityError, fmt.Errorf("redirect to %s: %w", rd.To, err), rd.From, rd.FSPath)
+			continue
+		}
+		if err := snap.handle(MuxPattern(http.MethodGet, rd.From, true), redirectHandler(rd.To, rd.Status)); err != nil {
+			snap.log(SeverityError, fmt.Errorf("redirect to %s: %w", rd.To, err), rd.From, rd.FSPath)
+			continue
+		}
+		snap.redirected = append(snap.redirected, rd.From)
+	}
+}
+
+// conflict returns an error if urlpath is served by a route, is below a handler directory or has been redirected already.
+func (snap *snapshot) conflict(urlpath string) error {
+	for _, r := range snap.routes {
+		if r.URLPath == urlpath {
+			return errors.New("conflicts with an existing page")
+		}
+	}
+	for _, mount := range snap.mounts {
+		if strings.HasPrefix(urlpath, mount+"/") {
+			return fmt.Errorf("conflicts with handler directory %s", mount)
+		}
+	}
+	for _, from := range snap.redirected {
+		if from == urlpath {
+			return errors.New("conflicts with another redirect")
+		}
+	}
+	return nil
+}
+
+func redirectHandler(target string, status int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target, status)
+	}
+}
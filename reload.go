@@ -17,7 +17,7 @@ import (
 // We can't distinguish between local commits (which should be kept) and upstream history rewrites (which can be dropped).
 // Thus it fails if there are local changes and refuses to run from an interactive terminal.
 // You should know about "git reflog".
-func GitReloadHandler(secret string, osDir string, reload func()) http.HandlerFunc {
+func GitReloadHandler(secret string, osDir string, reload func() error) http.HandlerFunc {
 	if osDir == "" {
 		return http.NotFound
 	}
@@ -50,8 +50,7 @@ func GitReloadHandler(secret string, osDir string, reload func()) http.HandlerFu
 			return fmt.Errorf("error running git reset: %v", err)
 		}
 
-		reload()
-		return nil
+		return reload()
 	})
 
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -81,11 +80,9 @@ func GitReloadHandler(secret string, osDir string, reload func()) http.HandlerFu
 }
 
 // ReloadHandler returns a rate-limited handler which calls reload.
-func ReloadHandler(secret string, reload func()) http.HandlerFunc {
-	limitedReload := Limit(time.Minute, 2, func() error {
-		reload()
-		return nil
-	})
+// If reload returns an error (e. g. because the reload has been rejected), it is reported in the response.
+func ReloadHandler(secret string, reload func() error) http.HandlerFunc {
+	limitedReload := Limit(time.Minute, 2, reload)
 
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Query().Get("secret") != secret {
@@ -0,0 +1,318 @@
+package seal
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/wansing/seal/content"
+	"golang.org/x/net/html"
+)
+
+// A DocumentLister is a Lister which distinguishes documents (like blog posts) from listings (like index or archive pages) of documents.
+// Only documents are added to the search index. If a Lister is no DocumentLister, all its URL paths are considered documents.
+type DocumentLister interface {
+	Lister
+	DocumentURLPaths() []string
+}
+
+type SearchResult struct {
+	URLPath string `json:"urlpath"`
+	Title   string `json:"title"`
+	Snippet string `json:"snippet"`
+	Score   int    `json:"score"`
+}
+
+type searchDoc struct {
+	URLPath string
+	Title   string
+	Text    string
+}
+
+// searchIndex is an inverted index. It is built by Reload and not modified afterwards.
+type searchIndex struct {
+	docs  []searchDoc
+	terms map[string]map[int]int // term -> doc index -> score
+}
+
+func searchTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// buildSearchIndex renders all documents of the snapshot and indexes their text. Static files, feeds and dynamic pages are skipped.
+func buildSearchIndex(snap *snapshot) *searchIndex {
+	idx := &searchIndex{
+		terms: make(map[string]map[int]int),
+	}
+	for _, r := range snap.routes {
+		if r.Static || r.Feed || r.Dynamic || r.Listing || r.Negotiated {
+			continue
+		}
+		resp := render(snap.mux, r.URLPath)
+		if resp.code != http.StatusOK {
+			continue
+		}
+
+		title, text := extractText(resp.body.Bytes())
+		if title == "" {
+			title = r.URLPath
+		}
+		id := len(idx.docs)
+		idx.docs = append(idx.docs, searchDoc{
+			URLPath: r.URLPath,
+			Title:   title,
+			Text:    text,
+		})
+		for _, term := range searchTerms(title) {
+			idx.add(term, id, 5) // title matches weigh more
+		}
+		for _, term := range searchTerms(text) {
+			idx.add(term, id, 1)
+		}
+	}
+	return idx
+}
+
+func (idx *searchIndex) add(term string, id int, score int) {
+	if idx.terms[term] == nil {
+		idx.terms[term] = make(map[int]int)
+	}
+	idx.terms[term][id] += score
+}
+
+// Search returns the documents which contain all terms of the query, best matches first.
+// The last term also matches as prefix, so results can be shown while typing.
+func (idx *searchIndex) Search(query string, limit int) []SearchResult {
+	if idx == nil {
+		return nil
+	}
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var scores map[int]int
+	for i, term := range terms {
+		var matches = make(map[int]int)
+		for id, score := range idx.terms[term] {
+			matches[id] += score
+		}
+		if i == len(terms)-1 {
+			for indexed, postings := range idx.terms {
+				if indexed != term && strings.HasPrefix(indexed, term) {
+					for id, score := range postings {
+						matches[id] += score
+					}
+				}
+			}
+		}
+
+		if scores == nil {
+			scores = matches
+			continue
+		}
+		for id := range scores {
+			if matches[id] == 0 {
+				delete(scores, id) // all terms must match
+			} else {
+				scores[id] += matches[id]
+			}
+		}
+	}
+
+	var results []SearchResult
+	for id, score := range scores {
+		doc := idx.docs[id]
+		results = append(results, SearchResult{
+			URLPath: doc.URLPath,
+			Title:   doc.Title,
+			Snippet: snippet(doc.Text, terms[0]),
+			Score:   score,
+		})
+	}
+	slices.SortFunc(results, func(a, b SearchResult) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), strings.Compare(a.URLPath, b.URLPath))
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// snippet returns about 160 characters of text around the first occurrence of term.
+func snippet(text, term string) string {
+	const length = 160
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text)) // same length for almost all scripts
+	pos := 0
+	if i := strings.Index(string(lower), term); i >= 0 && len(lower) == len(runes) {
+		pos = len([]rune(string(lower)[:i]))
+	}
+	begin := max(0, pos-length/4)
+	end := min(len(runes), begin+length)
+	result := strings.TrimSpace(string(runes[begin:end]))
+	if begin > 0 {
+		result = "…" + result
+	}
+	if end < len(runes) {
+		result += "…"
+	}
+	return result
+}
+
+// extractText returns the title (from the first heading or the title element) and the text of htm.
+// If htm contains a main element, only its text is returned. Scripts and styles are skipped.
+func extractText(htm []byte) (string, string) {
+	var tokenizer = html.NewTokenizer(bytes.NewReader(htm))
+	var title, heading strings.Builder
+	var all, main strings.Builder
+	var skip, inTitle, inHeading, inMain, hasMain bool
+	var headingDone bool
+
+	for {
+		tt := tokenizer.Next()
+		if tt == html.ErrorToken {
+			break // assuming tokenizer.Err() == io.EOF
+		}
+		tagNameBytes, _ := tokenizer.TagName()
+		tagName := string(tagNameBytes)
+
+		switch tt {
+		case html.StartTagToken:
+			switch tagName {
+			case "script", "style":
+				skip = true
+			case "title":
+				inTitle = true
+			case "h1", "h2", "h3", "h4":
+				inHeading = !headingDone
+			case "main":
+				inMain, hasMain = true, true
+			}
+		case html.EndTagToken:
+			switch tagName {
+			case "script", "style":
+				skip = false
+			case "title":
+				inTitle = false
+			case "h1", "h2", "h3", "h4":
+				if inHeading {
+					inHeading, headingDone = false, true
+				}
+			case "main":
+				inMain = false
+			}
+			all.WriteByte(' ') // separate words in adjacent elements
+			main.WriteByte(' ')
+		case html.TextToken:
+			if skip {
+				continue
+			}
+			text := string(tokenizer.Text())
+			switch {
+			case inTitle:
+				title.WriteString(text)
+				continue
+			case inHeading:
+				heading.WriteString(text)
+			}
+			all.WriteString(text)
+			if inMain {
+				main.WriteString(text)
+			}
+		}
+	}
+
+	var text = all.String()
+	if hasMain {
+		text = main.String()
+	}
+	var resultTitle = strings.TrimSpace(heading.String())
+	if resultTitle == "" {
+		resultTitle = strings.TrimSpace(title.String())
+	}
+	return strings.Join(strings.Fields(resultTitle), " "), strings.Join(strings.Fields(text), " ")
+}
+
+// SearchHandler returns a handler which searches the current snapshot for the query parameter "q" and sends the results in JSON.
+// The optional query parameter "limit" limits the number of results.
+func (srv *Server) SearchHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var results = []SearchResult{} // json "[]" instead of "null"
+		if snap := srv.current.Load(); snap != nil {
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			if found := snap.search.Search(r.URL.Query().Get("q"), limit); found != nil {
+				results = found
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		enc.Encode(results)
+	}
+}
+
+type searchData struct {
+	snap *snapshot
+}
+
+// searchFuncs sets the template function "search" of t to return the searchData of snap.
+// Clones of t keep it, so pages render the results from the index of their own snapshot.
+func searchFuncs(t *template.Template, snap *snapshot) {
+	t.Funcs(template.FuncMap{
+		"search": func() searchData {
+			return searchData{snap: snap}
+		},
+	})
+}
+
+// Query returns the query parameter "q".
+func (data searchData) Query(requestURL *url.URL) string {
+	return requestURL.Query().Get("q")
+}
+
+func (data searchData) Results(requestURL *url.URL) []SearchResult {
+	return data.snap.search.Search(data.Query(requestURL), 50)
+}
+
+// Search is a ContentFunc which renders a search form and the results for the query parameter "q".
+// The filecontent can replace the default template.
+// It searches the index of the snapshot which the page belongs to, using the template function "search" which Reload sets,
+// so it can't be parsed into templates of other origin.
+func Search(t *template.Template, urlpath, fileroot string, filecontent []byte) error {
+	var text = string(filecontent)
+	if strings.TrimSpace(text) == "" {
+		text = `{{$query := .Query $.RequestURL}}
+		<form method="get" action="{{$.URLPath}}">
+			<input type="search" name="q" value="{{$query}}">
+			<button type="submit">{{$.T "Search"}}</button>
+		</form>
+		{{if $query}}
+			{{with .Results $.RequestURL}}
+				<ul>
+					{{range .}}
+						<li>
+							<a href="{{.URLPath}}">{{.Title}}</a>
+							<p>{{.Snippet}}</p>
+						</li>
+					{{end}}
+				</ul>
+			{{else}}
+				<p>{{$.T "No results."}}</p>
+			{{end}}
+		{{end}}`
+	}
+
+	content.MarkDynamic(t)
+	_, err := t.Parse("{{with search}}" + text + "{{end}}")
+	return err
+}
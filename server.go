@@ -1,15 +1,23 @@
 package seal
 
 import (
-	"encoding/json"
+	"bytes"
+	"fmt"
 	"html/template"
 	"io"
 	"io/fs"
 	"log"
+	"maps"
 	"net/http"
 	"net/url"
 	"path"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wansing/seal/content"
 )
 
 // A ContentFunc populates the template t.
@@ -17,94 +25,379 @@ import (
 // The fileroot is useful to distinguish between multiple instances of this content on the same page.
 type ContentFunc func(t *template.Template, urlpath, fileroot string, filecontent []byte) error
 
-type Error struct {
-	URLPath string `json:"urlpath"`
-	Err     error  `json:"error"`
-}
-
 // handler must handle full paths (including urlpath prefix)
 //
 // map[string]ContentFunc is provided in case the handler reads any content files
-type HandlerGen func(fsys fs.FS, urlpath string, t *template.Template, content map[string]ContentFunc) http.Handler
+//
+// If the handler fails to render a response, it should call ServeError.
+//
+// A returned error is recorded in the error list of the snapshot. If the handler is not nil, it is mounted anyway.
+type HandlerGen func(fsys fs.FS, urlpath string, t *template.Template, content map[string]ContentFunc) (http.Handler, error)
 
-type Server struct {
-	*http.ServeMux // not func (*Server) Handler() because we create a new handler on reload
-	FS             fs.FS
-	Content        map[string]ContentFunc // key is file extension
-	Handlers       map[string]HandlerGen
+// A Publisher keeps state outside of the snapshot, e.g. for use in ContentFuncs.
+// Publish is called when a snapshot goes live, so the state is swapped together with the ServeMux.
+//
+// If an http.Handler returned by a HandlerGen is a Publisher, it is notified when its snapshot goes live.
+// Then the publishers in Server.Publishers are notified. They are notified about every snapshot, even if it has no handlers.
+type Publisher interface {
+	Publish(generation int)
+}
 
-	errs []Error
+// A Lister is an http.Handler, returned by a HandlerGen, which can list the URL paths it serves, e.g. for Export.
+type Lister interface {
+	URLPaths() []string
 }
 
-func (srv *Server) log(err error, urlpath ...string) {
-	log.Printf("%s: %v", path.Join(urlpath...), err)
-	srv.errs = append(srv.errs, Error{
-		URLPath: path.Join(urlpath...),
-		Err:     err,
-	})
+// A LastModifier is a Lister which knows when the content of its URL paths has been modified, e.g. for the sitemap.
+// It returns the zero time if unknown.
+type LastModifier interface {
+	Lister
+	LastModified(urlpath string) time.Time
 }
 
-// ErrorsHandler returns a handler which sends srv.errs in JSON.
-func (srv *Server) ErrorsHandler() http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		var errs = srv.errs
-		if errs == nil {
-			errs = []Error{} // json "[]" instead of "null"
+// A FeedLister is a Lister which serves feeds, like feed.xml. They are exported under their name and left out of the sitemap and the search index.
+type FeedLister interface {
+	Lister
+	FeedURLPaths() []string
+}
+
+// A route is a URL path which has been registered by readDir.
+type route struct {
+	URLPath    string
+	Static     bool // static file, served as it is
+	Feed       bool // see FeedLister
+	Dynamic    bool // output depends on the request or on the current time, see content.MarkDynamic
+	Listing    bool // lists documents, see DocumentLister
+	Negotiated bool // serves one of the language variants, depending on the request
+	LastMod    time.Time
+}
+
+// page collects the content files of a directory.
+type page struct {
+	hasContent  bool
+	meta        content.Meta            // merged front matter
+	langMeta    map[string]content.Meta // merged front matter of language variants like "main.de.md", key is language
+	bases       []string                // names of templates without language
+	variants    []string                // names of templates with language, like "main.de"
+	dynamic     []string                // names of templates which have been marked dynamic, see content.MarkDynamic
+	frontMatter map[string]int          // template name => number of front matter lines
+	modTime     time.Time               // of the most recently modified content file, including inherited ones
+}
+
+// A scope describes the templates which apply in a directory. Subdirectories inherit it along with the templates.
+type scope struct {
+	modTime     time.Time       // of the most recently modified content file
+	variants    map[string]bool // language variants of templates, see langTmpl
+	dynamic     map[string]bool // names of dynamic templates, see content.MarkDynamic
+	frontMatter map[string]int  // template name => number of front matter lines, see frontMatterError
+}
+
+// scopeOf returns the scope of the directory whose files have been read into pg. The directory inherits sc.
+func (srv *Server) scopeOf(sc scope, pg *page) scope {
+	var frontMatter = maps.Clone(sc.frontMatter)
+	if frontMatter == nil {
+		frontMatter = make(map[string]int)
+	}
+	maps.Copy(frontMatter, pg.frontMatter)
+	return scope{
+		modTime:     pg.modTime,
+		variants:    srv.dirTemplates(sc.variants, pg, pg.variants),
+		dynamic:     srv.dirTemplates(sc.dynamic, pg, pg.dynamic),
+		frontMatter: frontMatter,
+	}
+}
+
+// templateError adds the front matter lines of the scope to the line of a template error.
+func (sc scope) templateError(err error) error {
+	return frontMatterError{err: err, lines: sc.frontMatter}
+}
+
+// variantMeta returns the front matter of the language variant. Its keys take precedence.
+func (pg *page) variantMeta(lang string) content.Meta {
+	var meta = maps.Clone(pg.meta)
+	maps.Copy(meta, pg.langMeta[lang])
+	return meta
+}
+
+// A snapshot is the result of reading the content tree. It is built by Reload and published atomically.
+type snapshot struct {
+	generation  int
+	mux         *http.ServeMux
+	errs        []Error // guarded by errsLock, because errors are also recorded while serving requests
+	errsLock    sync.Mutex
+	runtimeErrs int // guarded by errsLock
+	publishers  []Publisher
+	routes      []route
+	search      *searchIndex
+	mounts      []string   // URL paths of handler directories
+	redirects   []redirect // collected by readDir
+	redirected  []string   // URL paths of registered redirects
+	known       map[string]bool
+	patterns    map[string]bool // registered on mux, see handle
+	published   time.Time       // when the state of the publishers has been swapped, zero if there are no publishers
+}
+
+// lastMod returns the later of modTime and the time when the state of the publishers has been swapped, because cached pages may include it.
+func (snap *snapshot) lastMod(modTime time.Time) time.Time {
+	if snap.published.After(modTime) {
+		return snap.published
+	}
+	return modTime
+}
+
+type Server struct {
+	FS       fs.FS
+	Content  map[string]ContentFunc // key is file extension
+	Handlers map[string]HandlerGen
+
+	// Publishers are notified when a snapshot goes live, after the handlers of the snapshot.
+	Publishers []Publisher
+
+	// SafeReload keeps the previous snapshot live if a reload produces errors (warnings are accepted).
+	// The errors of the rejected snapshot are still available through ErrorsHandler.
+	SafeReload bool
+
+	// BaseURL like "https://example.com" is used for absolute URLs in the sitemap. If it is empty, it is derived from the request.
+	BaseURL string
+
+	// SitemapStatic adds static files to the sitemap.
+	SitemapStatic bool
+
+	// TrailingSlash makes URL paths of pages end with a slash, like "/about/", instead of "/about".
+	// Requests for the other form are redirected. Static files never have a trailing slash. Handler directories have their own URL paths.
+	TrailingSlash bool
+
+	// Lowercase redirects requests for unknown URL paths to their lowercase form, if it is known.
+	Lowercase bool
+
+	// Languages enables language variants of content files, like "main.de.md" and "main.en.md".
+	// A page with variants is served at a language prefix like "/de/about", and without prefix in the language which matches the Accept-Language header best.
+	// The first language is the default. It is used for pages without variants, unless their front matter has a "lang" key.
+	Languages []string
+
+	current    atomic.Pointer[snapshot] // not func (*Server) Handler() because we create a new snapshot on reload
+	latest     atomic.Pointer[snapshot] // most recently built snapshot, might have been rejected
+	reloadLock sync.Mutex               // serializes reloads
+	generation int                      // guarded by reloadLock
+}
+
+// ServeHTTP serves the request using the snapshot which has been published by the latest Reload.
+func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	snap := srv.current.Load()
+	if snap == nil {
+		http.NotFound(w, r) // not loaded yet
+		return
+	}
+	if target, ok := snap.canonical(r.URL.Path, srv.Lowercase); ok {
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
 		}
-		enc := json.NewEncoder(w)
-		enc.SetIndent("", "\t")
-		enc.Encode(errs)
+		var code = http.StatusMovedPermanently
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			code = http.StatusPermanentRedirect // keeps method and body
+		}
+		http.Redirect(w, r, target, code)
+		return
 	}
+	snap.mux.ServeHTTP(w, r)
 }
 
-func (srv *Server) readDir(mux *http.ServeMux, tmpl *template.Template, fspath string, urlpath string) {
+// MuxPattern returns the http.ServeMux pattern for method (empty for all methods) and urlpath.
+// If urlpath ends with a slash, the pattern matches the subtree, unless exact is true.
+// The segments of urlpath are escaped, so characters like "{" and spaces in file names are matched literally.
+// A HandlerGen should use it for URL paths which are derived from file names.
+func MuxPattern(method, urlpath string, exact bool) string {
+	segments := strings.Split(urlpath, "/")
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+	pattern := strings.Join(segments, "/")
+	if exact && strings.HasSuffix(pattern, "/") {
+		pattern += "{$}" // don't match subtree
+	}
+	if method != "" {
+		pattern = method + " " + pattern
+	}
+	return pattern
+}
+
+// handle registers h on snap.mux, or returns an error if pattern has been registered already.
+// The pattern must have been made by MuxPattern. Then different patterns don't conflict:
+// patterns with a method are exact, and subtrees have distinct URL paths (see claim).
+func (snap *snapshot) handle(pattern string, h http.Handler) error {
+	if snap.patterns[pattern] {
+		return fmt.Errorf("%s is served already", pattern)
+	}
+	snap.mux.Handle(pattern, h)
+	snap.patterns[pattern] = true
+	return nil
+}
+
+// readDir reads the directory fspath. The template function "nav" of tmpl must return nav.
+// The scope sc describes the templates which tmpl has been read from.
+func (srv *Server) readDir(snap *snapshot, tmpl *template.Template, sc scope, fspath string, urlpath string, nav *NavNode) {
 	entries, err := fs.ReadDir(srv.FS, fspath)
 	if err != nil {
-		srv.log(err, urlpath)
+		snap.log(SeverityError, err, urlpath, fspath)
 	}
 
+	dirMeta := srv.readDirMeta(snap, fspath, urlpath)
+	nav.apply(dirMeta)
+	srv.readRedirects(snap, fspath, urlpath)
+
 	// read files
-	var hasContent = false
+	var pg = &page{
+		meta:        make(content.Meta),
+		langMeta:    make(map[string]content.Meta),
+		frontMatter: make(map[string]int),
+		modTime:     sc.modTime,
+	}
 	for _, entry := range entries {
-		err := srv.readFile(mux, tmpl, fspath, urlpath, &hasContent, entry)
+		err := srv.readFile(snap, tmpl, fspath, urlpath, pg, entry)
 		if err != nil {
-			srv.log(err, urlpath, entry.Name())
+			snap.log(SeverityError, err, urlpath, path.Join(fspath, entry.Name()))
 		}
 	}
 
 	// make "html" template default after it has been loaded, so that Execute works out of the box
+	var layoutTmpl = tmpl
 	if h := tmpl.Lookup("html"); h != nil {
-		tmpl = h
+		layoutTmpl = h
 	}
 
+	// subdirectories don't inherit $
+	var dirScope = srv.scopeOf(sc, pg)
+
 	// use separate template for $
-	dollarTmpl, _ := tmpl.Clone()
+	dollarTmpl, _ := layoutTmpl.Clone()
 
 	// read files in $ subdir
 	dollarEntries, _ := fs.ReadDir(srv.FS, path.Join(fspath, "$"))
 	for _, entry := range dollarEntries {
-		err := srv.readFile(mux, dollarTmpl, path.Join(fspath, "$"), urlpath, &hasContent, entry)
+		err := srv.readFile(snap, dollarTmpl, path.Join(fspath, "$"), urlpath, pg, entry)
 		if err != nil {
-			srv.log(err, urlpath, entry.Name())
+			snap.log(SeverityError, err, urlpath, path.Join(fspath, "$", entry.Name()))
 		}
 	}
+	var pageScope = srv.scopeOf(sc, pg)
+	var variants = pageScope.variants
 
-	// register template handler for this directory
-	if hasContent {
-		h, err := templateHandler(dollarTmpl, urlpath)
-		if err != nil {
-			srv.log(err, urlpath)
+	// register redirect or template handler for this directory
+	var pageURLPath = srv.pageURLPath(urlpath)
+	if target := dirMeta.String("redirect"); target != "" {
+		nav.Page = true
+		nav.URLPath = pageURLPath
+		if err := snap.handle(MuxPattern(http.MethodGet, pageURLPath, true), redirectHandler(target, http.StatusSeeOther)); err != nil {
+			snap.log(SeverityError, err, urlpath, fspath)
+		} else {
+			snap.redirected = append(snap.redirected, pageURLPath)
+		}
+		if pg.hasContent {
+			snap.log(SeverityWarning, fmt.Errorf("content is not served because of redirect to %s", target), urlpath, fspath)
 		}
+	} else if pg.hasContent {
+		nav.Page = true
+		nav.URLPath = pageURLPath
 
-		if urlpath == "/" {
-			mux.HandleFunc("GET /{$}", h)
+		var lastMod = pg.meta.Updated()
+		if lastMod.IsZero() {
+			lastMod = pg.modTime
+		}
+		var dynamic = len(pageScope.dynamic) > 0
+
+		var langs []string // of variants
+		for _, lang := range srv.Languages {
+			if pg.langMeta[lang] != nil {
+				langs = append(langs, lang)
+			}
+		}
+
+		if len(langs) == 0 {
+			var lang = pg.meta.String("lang")
+			if lang == "" && len(srv.Languages) > 0 {
+				lang = srv.Languages[0]
+			}
+			if dirMeta.Title() == "" {
+				nav.Title = navTitle(dollarTmpl, variantName("main", lang, variants), pg.meta.Title(), nav.Title)
+			}
+			h := srv.pageHandler(snap, dollarTmpl, pageScope, fspath, TemplateData{
+				URLPath: urlpath,
+				Meta:    pg.meta,
+				Lang:    lang,
+			})
+			if !snap.addPage(route{URLPath: pageURLPath, Dynamic: dynamic, LastMod: lastMod}, h, urlpath, fspath) {
+				nav.Page = false
+			}
 		} else {
-			mux.HandleFunc("GET "+urlpath, h) // urlpath is without trailing slash, so it's not a prefix match
-			mux.HandleFunc("GET "+urlpath+".html", redirectHTMLHandler)
+			var translations []Translation
+			for _, lang := range langs {
+				translations = append(translations, Translation{
+					Lang: lang,
+					URL:  srv.absURL(srv.langURLPath(lang, urlpath)),
+				})
+			}
+			if dirMeta.Title() == "" {
+				nav.Title = navTitle(dollarTmpl, variantName("main", langs[0], variants), pg.variantMeta(langs[0]).Title(), nav.Title)
+			}
+			var handlers = make(map[string]http.Handler)
+			var variantLangs []string // successfully registered
+			for _, lang := range langs {
+				var meta = pg.variantMeta(lang)
+				var langURLPath = srv.langURLPath(lang, urlpath)
+				h := srv.pageHandler(snap, dollarTmpl, pageScope, fspath, TemplateData{
+					URLPath:      urlpath,
+					Meta:         meta,
+					Lang:         lang,
+					Translations: translations,
+					defaultURL:   srv.absURL(pageURLPath),
+				})
+				if !snap.addPage(route{URLPath: langURLPath, Dynamic: dynamic, LastMod: lastMod}, h, urlpath, fspath) {
+					continue
+				}
+				handlers[lang] = h
+				variantLangs = append(variantLangs, lang)
+				var title string // empty falls back to nav.Title
+				if dirMeta.Title() == "" {
+					title = navTitle(dollarTmpl, variantName("main", lang, variants), meta.Title(), nav.Title)
+				}
+				nav.addTranslation(lang, langURLPath, title)
+				snap.addAliases(pg.langMeta[lang], urlpath, langURLPath, fspath)
+			}
+			if len(variantLangs) == 0 || !snap.addPage(route{URLPath: pageURLPath, Negotiated: true, Dynamic: dynamic, LastMod: lastMod}, negotiateHandler(variantLangs, handlers), urlpath, fspath) {
+				nav.Page = false
+			}
+		}
+
+		if nav.Page {
+			snap.addAliases(pg.meta, urlpath, pageURLPath, fspath)
+			if urlpath != "/" {
+				snap.handle(MuxPattern(http.MethodGet, urlpath+".html", true), http.HandlerFunc(redirectHTMLHandler)) // a file of that name takes precedence
+			}
+		}
+	}
+
+	// register not found handler for the subtree, so the nearest layout is used
+	var defaultLang string
+	if len(srv.Languages) > 0 {
+		defaultLang = srv.Languages[0]
+	}
+	srv.addNotFound(snap, layoutTmpl, dirScope.variants, fspath, urlpath, "", defaultLang)
+	if urlpath == "/" || len(pg.langMeta) > 0 { // not in untranslated directories, so their URL paths with language prefix are not redirected to the subtree
+		for _, lang := range srv.Languages {
+			srv.addNotFound(snap, layoutTmpl, dirScope.variants, fspath, urlpath, "/"+lang, lang)
 		}
 	}
 
-	// subdirs
+	// subdirs, whose names might result in the same URL path, like "01-about" and "about"
+	var claimed = make(map[string]string) // URL path => entry name
+	var claim = func(entry fs.DirEntry, suburlpath string) bool {
+		if other, ok := claimed[suburlpath]; ok {
+			snap.log(SeverityError, fmt.Errorf("%s and %s have the same URL path", other, entry.Name()), suburlpath, path.Join(fspath, entry.Name()))
+			return false
+		}
+		claimed[suburlpath] = entry.Name()
+		return true
+	}
 	for _, entry := range entries {
 		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || entry.Name() == "$" {
 			continue
@@ -113,69 +406,305 @@ func (srv *Server) readDir(mux *http.ServeMux, tmpl *template.Template, fspath s
 		ext := path.Ext(entry.Name())
 		switch {
 		case ext == "":
-			clonedTmpl, _ := tmpl.Clone() // always clone because we may have multiple subdirs
+			// always clone because we may have multiple subdirs, but not layoutTmpl, because "html" can't be redefined in a clone of it
+			// the clone still contains "html", so the subdir inherits the layout unless it has its own
+			clonedTmpl, _ := tmpl.Clone()
+			child := newNavNode(urlpath, entry.Name())
+			if !claim(entry, child.URLPath) {
+				continue
+			}
+			navFuncs(clonedTmpl, child)
 			srv.readDir(
-				mux,
+				snap,
 				clonedTmpl,
+				dirScope,
 				path.Join(fspath, entry.Name()),
-				path.Join(urlpath, MakeSlug(entry.Name())),
+				child.URLPath,
+				child,
 			)
+			nav.addChild(child)
 		case srv.Handlers[ext] == nil:
 			// skip unknown extension
 		default:
-			clonedTmpl, _ := tmpl.Clone() // always clone because we may have multiple subdirs
-			subfs, _ := fs.Sub(srv.FS, entry.Name())
-			suburlpath := path.Join(urlpath, strings.TrimSuffix(entry.Name(), ext))
-			mux.Handle(suburlpath+"/", srv.Handlers[ext]( // trailing slash in order to to match subtree
+			clonedTmpl, _ := langTmpl(layoutTmpl, defaultLang, dirScope.variants).Clone() // always clone because we may have multiple subdirs
+			subfspath := path.Join(fspath, entry.Name())
+			subfs, err := fs.Sub(srv.FS, subfspath)
+			if err != nil {
+				snap.log(SeverityError, err, urlpath, subfspath)
+				continue
+			}
+			child := newNavNode(urlpath, strings.TrimSuffix(entry.Name(), ext))
+			child.Slug = child.Title // handlers are mounted at their directory name, without numeric prefix but not slugified
+			child.URLPath = path.Join(urlpath, child.Slug)
+			if !claim(entry, child.URLPath) {
+				continue
+			}
+			child.apply(srv.readDirMeta(snap, subfspath, child.URLPath))
+			child.Page = true
+			suburlpath := child.URLPath
+			child.URLPath += "/"
+			navFuncs(clonedTmpl, child)
+			errTmpl := errorPageTmpl(clonedTmpl, "500") // before the handler executes clonedTmpl
+			h, err := srv.Handlers[ext](
 				subfs,
 				suburlpath,
 				clonedTmpl,
 				srv.Content,
-			))
+			)
+			if err != nil {
+				snap.log(SeverityError, err, suburlpath, subfspath)
+			}
+			if h == nil {
+				continue
+			}
+			if err := snap.handle(MuxPattern("", suburlpath+"/", false), snap.withErrorPage(h, errTmpl, subfspath, defaultLang)); err != nil { // trailing slash in order to to match subtree
+				snap.log(SeverityError, err, suburlpath, subfspath)
+				continue
+			}
+			nav.addChild(child)
+			if p, ok := h.(Publisher); ok {
+				snap.publishers = append(snap.publishers, p)
+			}
+			if l, ok := h.(Lister); ok {
+				dl, isDocumentLister := h.(DocumentLister)
+				var documents []string
+				if isDocumentLister {
+					documents = dl.DocumentURLPaths()
+				}
+				fl, isFeedLister := h.(FeedLister)
+				var feeds []string
+				if isFeedLister {
+					feeds = fl.FeedURLPaths()
+				}
+				lm, isLastModifier := h.(LastModifier)
+				for _, u := range l.URLPaths() {
+					r := route{
+						URLPath: u,
+						Feed:    slices.Contains(feeds, u),
+						Listing: isDocumentLister && !slices.Contains(documents, u),
+					}
+					if isLastModifier {
+						r.LastMod = lm.LastModified(u)
+					}
+					snap.routes = append(snap.routes, r)
+				}
+			}
+			snap.mounts = append(snap.mounts, suburlpath)
 		}
 	}
 }
 
-func (srv *Server) readFile(mux *http.ServeMux, tmpl *template.Template, fspath string, urlpath string, hasContent *bool, entry fs.DirEntry) error {
+// pageHandler returns the handler of a page in the language data.Lang, which selects the language variants of the templates (see langTmpl).
+func (srv *Server) pageHandler(snap *snapshot, dollarTmpl *template.Template, sc scope, fspath string, data TemplateData) http.HandlerFunc {
+	var pageTmpl = langTmpl(dollarTmpl, data.Lang, sc.variants)
+	if layout := data.Meta.Layout(); layout != "" {
+		if l := pageTmpl.Lookup(layout); l != nil {
+			pageTmpl = l
+		} else {
+			snap.log(SeverityWarning, fmt.Errorf("layout template %q not found", layout), data.URLPath, fspath)
+		}
+	}
+
+	errTmpl := errorPageTmpl(pageTmpl, "500")
+	h, err := snap.templateHandler(pageTmpl, errTmpl, fspath, data, sc)
+	if err != nil {
+		snap.log(SeverityError, sc.templateError(err), data.URLPath, fspath)
+	}
+	if data.Lang == "" {
+		return h
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Language", data.Lang)
+		h(w, r)
+	}
+}
+
+// addPage registers h for r.URLPath and adds r to the routes. If the URL path is taken, an error is recorded and false is returned.
+func (snap *snapshot) addPage(r route, h http.Handler, urlpath, fspath string) bool {
+	if err := snap.handle(MuxPattern(http.MethodGet, r.URLPath, true), h); err != nil {
+		snap.log(SeverityError, err, urlpath, fspath)
+		return false
+	}
+	snap.handle(MuxPattern("", r.URLPath, true), http.HandlerFunc(methodNotAllowedHandler)) // else other methods would reach the not found handler of the subtree, see addNotFound
+	snap.routes = append(snap.routes, r)
+	return true
+}
+
+// addAliases collects the redirects from the "aliases" front matter key to target. Relative aliases are relative to the parent of urlpath.
+func (snap *snapshot) addAliases(meta content.Meta, urlpath, target, fspath string) {
+	for _, alias := range meta.Strings("aliases") {
+		snap.redirects = append(snap.redirects, redirect{
+			From:   resolveURLPath(path.Dir(urlpath), alias),
+			To:     target,
+			Status: http.StatusMovedPermanently,
+			FSPath: fspath,
+		})
+	}
+}
+
+// addNotFound registers a not found handler for the subtree urlpath below prefix, if layoutTmpl has a "404" template in the language lang.
+// It is registered without method, so it doesn't conflict with handler directories. Other methods than GET and HEAD get a plain 404 reply, like from http.ServeMux.
+func (srv *Server) addNotFound(snap *snapshot, layoutTmpl *template.Template, variants map[string]bool, fspath, urlpath, prefix, lang string) {
+	notFoundTmpl := errorPageTmpl(langTmpl(layoutTmpl, lang, variants), "404")
+	if notFoundTmpl == nil {
+		return
+	}
+	err := snap.handle(MuxPattern("", prefix+strings.TrimSuffix(urlpath, "/")+"/", false), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.NotFound(w, r)
+			return
+		}
+		snap.serveError(w, r, notFoundTmpl, http.StatusNotFound, TemplateData{
+			RequestURL: r.URL,
+			URLPath:    urlpath,
+			Lang:       lang,
+		})
+	}))
+	if err != nil {
+		snap.log(SeverityError, err, urlpath, fspath)
+	}
+}
+
+// readFile adds the front matter of content files to pg.meta. Keys of the "main" file take precedence.
+func (srv *Server) readFile(snap *snapshot, tmpl *template.Template, fspath string, urlpath string, pg *page, entry fs.DirEntry) error {
 	if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
 		return nil
 	}
 
+	var modTime time.Time
+	if info, err := entry.Info(); err == nil {
+		modTime = info.ModTime()
+	}
+
 	// if extension is unknown, then serve as static file
 	ext := path.Ext(entry.Name())
 	if srv.Content[ext] == nil {
-		mux.HandleFunc("GET "+path.Join(urlpath, entry.Name()), func(w http.ResponseWriter, r *http.Request) {
+		err := snap.handle(MuxPattern(http.MethodGet, path.Join(urlpath, entry.Name()), true), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			http.ServeFileFS(w, r, srv.FS, path.Join(fspath, entry.Name()))
+		}))
+		if err != nil {
+			return err
+		}
+		snap.handle(MuxPattern("", path.Join(urlpath, entry.Name()), true), http.HandlerFunc(methodNotAllowedHandler)) // see addPage
+		snap.routes = append(snap.routes, route{
+			URLPath: path.Join(urlpath, entry.Name()),
+			Static:  true,
+			LastMod: modTime,
 		})
 		return nil
 	}
 
-	*hasContent = true
 	fileroot := strings.TrimSuffix(entry.Name(), ext)
+	base, lang := srv.splitLang(fileroot)
+	if lang == "" {
+		pg.bases = append(pg.bases, base)
+	} else {
+		pg.variants = append(pg.variants, fileroot)
+	}
+	isErrorPage := slices.Contains(errorPageNames, base)
+	if !isErrorPage {
+		pg.hasContent = true
+		if modTime.After(pg.modTime) {
+			pg.modTime = modTime
+		}
+	}
 	filecontent, err := fs.ReadFile(srv.FS, path.Join(fspath, entry.Name()))
 	if err != nil {
 		return err
 	}
+	fileMeta, body, err := content.SplitFrontMatter(filecontent)
+	if err != nil {
+		return err
+	}
+	pg.frontMatter[fileroot] = strings.Count(string(filecontent[:len(filecontent)-len(body)]), "\n")
+	if !isErrorPage {
+		var meta = pg.meta
+		if lang != "" {
+			if pg.langMeta[lang] == nil {
+				pg.langMeta[lang] = make(content.Meta)
+			}
+			meta = pg.langMeta[lang]
+		}
+		for key, value := range fileMeta {
+			if _, exists := meta[key]; !exists || base == "main" {
+				meta[key] = value
+			}
+		}
+	}
 
-	return srv.Content[ext](tmpl.New(fileroot), urlpath, fileroot, filecontent)
+	t := tmpl.New(fileroot)
+	isDynamic, err := content.MarksDynamic(t, func() error {
+		return srv.Content[ext](t, urlpath, fileroot, body)
+	})
+	if isDynamic {
+		pg.dynamic = append(pg.dynamic, fileroot)
+	}
+	if err != nil {
+		return frontMatterError{err: err, lines: map[string]int{fileroot: pg.frontMatter[fileroot]}}
+	}
+	return nil
 }
 
-func (srv *Server) Reload() {
-	srv.errs = srv.errs[:0]
-	// don't mess with current mux (though live reload still won't be perfect, e. g. when deleting static files)
-	mux := http.NewServeMux()
-	srv.readDir(mux, template.New(""), ".", "/")
-	srv.ServeMux = mux
+// Reload reads srv.FS into a new snapshot and publishes it. In-flight requests keep using the previous snapshot.
+// Concurrent calls are serialized.
+//
+// If SafeReload is enabled and the new snapshot contains errors, it is not published and an error is returned.
+// Else errors are logged only.
+func (srv *Server) Reload() error {
+	srv.reloadLock.Lock()
+	defer srv.reloadLock.Unlock()
+
+	srv.generation++
+	snap := &snapshot{
+		generation: srv.generation,
+		mux:        http.NewServeMux(),
+		patterns:   make(map[string]bool),
+	}
+	rootNav := &NavNode{
+		URLPath: "/",
+	}
+	rootTmpl := template.New("")
+	navFuncs(rootTmpl, rootNav)
+	searchFuncs(rootTmpl, snap)
+	srv.readDir(snap, rootTmpl, scope{}, ".", "/", rootNav)
+	srv.addRedirects(snap)
+	srv.addSitemap(snap)
+	snap.addKnown()
+	srv.latest.Store(snap)
+
+	if prev := srv.current.Load(); srv.SafeReload && snap.hasErrors() && prev != nil {
+		err := fmt.Errorf("reload rejected because of errors, still serving generation %d", prev.generation)
+		log.Println(err)
+		return err
+	}
+
+	if len(snap.publishers) > 0 || len(srv.Publishers) > 0 {
+		snap.published = time.Now()
+	}
+	for _, p := range snap.publishers {
+		p.Publish(snap.generation)
+	}
+	for _, p := range srv.Publishers {
+		p.Publish(snap.generation)
+	}
+	snap.search = buildSearchIndex(snap) // after publishing, because cached pages are rendered on first request
+	srv.current.Store(snap)
+	return nil
 }
 
 type TemplateData struct {
-	RequestURL *url.URL // not the full request because that may leak cookies
-	URLPath    string
+	RequestURL   *url.URL // not the full request because that may leak cookies, and only the path on pages which are not dynamic (see content.MarkDynamic), because they are rendered once
+	URLPath      string
+	Meta         content.Meta  // front matter of the content files
+	Lang         string        // language of the page, if known
+	Translations []Translation // language variants of the page, including this one
+
+	defaultURL string // for hreflang="x-default"
 }
 
-// internalServerError replies to the request with an HTTP 500 internal server error.
-func internalServerError(w http.ResponseWriter, r *http.Request) {
-	http.Error(w, "500 internal server error", http.StatusInternalServerError)
+func methodNotAllowedHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Allow", "GET, HEAD")
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
 }
 
 func redirectHTMLHandler(w http.ResponseWriter, r *http.Request) {
@@ -186,43 +715,52 @@ func redirectHTMLHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func templateHandler(tmpl *template.Template, urlpath string) (http.HandlerFunc, error) {
+// templateHandler returns a handler which executes tmpl into a buffer. Unless the page is dynamic (see scope), the output is rendered once and sent with validators.
+// Last-Modified is the modification time of the content files in sc, or the publishing time of the snapshot, if it is later.
+// If execution fails, the error is recorded and errTmpl (see errorPageTmpl) is rendered with status code 500.
+func (snap *snapshot) templateHandler(tmpl, errTmpl *template.Template, fspath string, data TemplateData, sc scope) (http.HandlerFunc, error) {
+	// output does not depend on the request, unless the page is dynamic, so RequestURL has no query (see TemplateData)
+	data.RequestURL = &url.URL{Path: data.URLPath}
+
 	// test template execution, clone before so template can be extended later
 	t, err := tmpl.Clone()
+	if err == nil {
+		err = t.Execute(io.Discard, data)
+	}
 	if err != nil {
-		return internalServerError, err
+		return func(w http.ResponseWriter, r *http.Request) {
+			data := data // copy
+			data.RequestURL = r.URL
+			snap.serveError(w, r, errTmpl, http.StatusInternalServerError, data)
+		}, err
 	}
-	if err := t.Execute(io.Discard, TemplateData{
-		RequestURL: &url.URL{Path: urlpath},
-		URLPath:    urlpath,
-	}); err != nil {
-		return internalServerError, err
+
+	if len(sc.dynamic) > 0 {
+		return func(w http.ResponseWriter, r *http.Request) {
+			data := data // copy
+			data.RequestURL = r.URL
+			buf := getBuffer()
+			defer bufPool.Put(buf)
+			if err := tmpl.Execute(buf, data); err != nil {
+				snap.logRuntime(sc.templateError(err), r.URL.Path, fspath)
+				snap.serveError(w, r, errTmpl, http.StatusInternalServerError, data)
+				return
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write(buf.Bytes())
+		}, nil
 	}
 
+	var cache = &pageCache{}
 	return func(w http.ResponseWriter, r *http.Request) {
-		tmpl.Execute(w, TemplateData{
-			RequestURL: r.URL,
-			URLPath:    urlpath,
-		}) // ignore error, assume that initial execution test was enough
+		body, etag, err := cache.load(func(buf *bytes.Buffer) error {
+			return tmpl.Execute(buf, data)
+		})
+		if err != nil {
+			snap.logRuntime(sc.templateError(err), r.URL.Path, fspath)
+			snap.serveError(w, r, errTmpl, http.StatusInternalServerError, data)
+			return
+		}
+		cache.serve(w, r, body, etag, snap.lastMod(sc.modTime))
 	}, nil
 }
-
-// MakeSlug returns the input with [a-zA-Z0-9] retained and a dash in each gap.
-func MakeSlug(strs ...string) string {
-	var fields []string
-	for _, s := range strs {
-		fields = append(fields, strings.FieldsFunc(s, func(r rune) bool {
-			if 'a' <= r && r <= 'z' {
-				return false
-			}
-			if 'A' <= r && r <= 'Z' {
-				return false
-			}
-			if '0' <= r && r <= '9' {
-				return false
-			}
-			return true
-		})...)
-	}
-	return strings.Join(fields, "-")
-}
@@ -0,0 +1,84 @@
+package seal
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// RequestBaseURL returns baseURL without trailing slash or, if it is empty, derives the base URL from the request.
+func RequestBaseURL(baseURL string, r *http.Request) string {
+	if baseURL != "" {
+		return strings.TrimSuffix(baseURL, "/")
+	}
+	var scheme = "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// addSitemap registers /sitemap.xml and /robots.txt, unless the content tree provides them or redirects them.
+// The sitemap lists the routes which have been registered so far.
+func (srv *Server) addSitemap(snap *snapshot) {
+	if snap.conflict("/sitemap.xml") == nil {
+		var routes []route
+		for _, r := range snap.routes {
+			if r.Feed || r.Static && !srv.SitemapStatic {
+				continue
+			}
+			routes = append(routes, r)
+		}
+		snap.handle(MuxPattern(http.MethodGet, "/sitemap.xml", true), srv.sitemapHandler(routes))
+		snap.routes = append(snap.routes, route{
+			URLPath: "/sitemap.xml",
+			Static:  true,
+		})
+	}
+
+	if snap.conflict("/robots.txt") == nil {
+		snap.handle(MuxPattern(http.MethodGet, "/robots.txt", true), http.HandlerFunc(srv.robotsHandler))
+		snap.routes = append(snap.routes, route{
+			URLPath: "/robots.txt",
+			Static:  true,
+		})
+	}
+}
+
+func (srv *Server) sitemapHandler(routes []route) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		base := RequestBaseURL(srv.BaseURL, r)
+		var urlset sitemapURLSet
+		for _, route := range routes {
+			u := sitemapURL{
+				Loc: base + route.URLPath,
+			}
+			if !route.LastMod.IsZero() {
+				u.LastMod = route.LastMod.Format(time.RFC3339)
+			}
+			urlset.URLs = append(urlset.URLs, u)
+		}
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.Write([]byte(xml.Header))
+		enc := xml.NewEncoder(w)
+		enc.Indent("", "\t")
+		enc.Encode(urlset)
+	}
+}
+
+func (srv *Server) robotsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "User-agent: *\nDisallow:\n\nSitemap: %s/sitemap.xml\n", RequestBaseURL(srv.BaseURL, r))
+}
@@ -0,0 +1,84 @@
+package seal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode"
+)
+
+// transliterations maps Latin letters with diacritics to ASCII. Lowercase letters only, uppercase ones are derived.
+var transliterations = map[rune]string{
+	'ä': "ae", 'ö': "oe", 'ü': "ue", 'ß': "ss", // German
+	'æ': "ae", 'œ': "oe", 'ø': "oe", 'å': "aa", // Nordic
+	'þ': "th", 'ð': "d", 'đ': "d", 'ħ': "h", 'ı': "i", 'ł': "l", 'ŀ': "l", 'ŧ': "t",
+}
+
+func init() {
+	for ascii, letters := range map[string]string{
+		"a": "àáâãāăą",
+		"c": "çćĉċč",
+		"d": "ď",
+		"e": "èéêëēĕėęě",
+		"g": "ĝğġģ",
+		"h": "ĥ",
+		"i": "ìíîïĩīĭį",
+		"j": "ĵ",
+		"k": "ķ",
+		"l": "ĺļľ",
+		"n": "ñńņň",
+		"o": "òóôõōŏő",
+		"r": "ŕŗř",
+		"s": "śŝşšș",
+		"t": "ţťț",
+		"u": "ùúûũůūŭűų",
+		"w": "ŵ",
+		"y": "ýÿŷ",
+		"z": "źżž",
+	} {
+		for _, r := range letters {
+			transliterations[r] = ascii
+		}
+	}
+	for r, ascii := range transliterations {
+		if upper := unicode.ToUpper(r); upper != r {
+			transliterations[upper] = strings.ToUpper(ascii[:1]) + ascii[1:]
+		}
+	}
+	transliterations['ẞ'] = "SS"
+}
+
+// MakeSlug returns the input with letters and digits retained and a dash in each gap.
+// Latin letters with diacritics are transliterated to ASCII, like "Größe" to "Groesse". Letters of other scripts are retained.
+// If no letters or digits remain, a hash of the input is returned, so distinct inputs still get distinct slugs.
+func MakeSlug(strs ...string) string {
+	var fields []string
+	for _, s := range strs {
+		var b strings.Builder
+		var base rune // of decomposed letters
+		for _, r := range s {
+			switch {
+			case base < unicode.MaxASCII && unicode.Is(unicode.Mn, r): // combining mark on a Latin letter
+				if r == '\u0308' && strings.ContainsRune("aouAOU", base) { // diaeresis, like in decomposed "ü"
+					b.WriteByte('e')
+				}
+				continue // keep base, there might be more marks
+			case transliterations[r] != "":
+				b.WriteString(transliterations[r])
+			default:
+				b.WriteRune(r)
+			}
+			base = r
+		}
+		fields = append(fields, strings.FieldsFunc(b.String(), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.In(r, unicode.Mn, unicode.Mc) // marks of other scripts are retained
+		})...)
+	}
+	if len(fields) == 0 {
+		if input := strings.Join(strs, ""); strings.TrimSpace(input) != "" {
+			sum := sha256.Sum256([]byte(input))
+			return hex.EncodeToString(sum[:4])
+		}
+	}
+	return strings.Join(fields, "-")
+}
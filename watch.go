@@ -0,0 +1,76 @@
+package seal
+
+import (
+	"context"
+	"io/fs"
+	"log"
+	"maps"
+	"strings"
+	"time"
+)
+
+// fileStamp is used to detect changes. It works with any fs.FS, but might miss changes which keep modtime and size.
+type fileStamp struct {
+	ModTime int64 // unix nano, because time.Time contains a location and is not suitable for comparison with ==
+	Size    int64
+	Mode    fs.FileMode
+}
+
+// fingerprint walks fsys and returns a fileStamp for each file and directory. Like readDir, it skips hidden files and directories.
+func fingerprint(fsys fs.FS) map[string]fileStamp {
+	var stamps = make(map[string]fileStamp)
+	fs.WalkDir(fsys, ".", func(fspath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // skip, readDir will log the error
+		}
+		if fspath != "." && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return fs.SkipDir // e.g. .git
+			}
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		stamps[fspath] = fileStamp{
+			ModTime: info.ModTime().UnixNano(),
+			Size:    info.Size(),
+			Mode:    info.Mode(),
+		}
+		return nil
+	})
+	return stamps
+}
+
+// Watch walks srv.FS every interval and calls srv.Reload if files have been added, changed or removed.
+// Bursts of changes are debounced: Reload is called after the tree has not changed for one interval.
+// Watch blocks until ctx is done.
+func (srv *Server) Watch(ctx context.Context, interval time.Duration) {
+	var last = fingerprint(srv.FS)
+	var pending = false
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current := fingerprint(srv.FS)
+		if !maps.Equal(current, last) {
+			last = current
+			pending = true // wait until changes settle
+			continue
+		}
+		if pending {
+			pending = false
+			if err := srv.Reload(); err != nil {
+				log.Printf("watch: %v", err)
+			}
+		}
+	}
+}